@@ -0,0 +1,33 @@
+// Package blob stores file contents, such as attachments, outside the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+// Returned by Get and Delete when no blob is stored under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Stores blobs by key. Keys are slash-separated paths chosen by the caller, such as "attachments/ab12".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Builds the storage backend selected by `cfg.Storage`.
+func New(cfg config.AttachmentsConfig) (Storage, error) {
+	switch cfg.Storage {
+	case "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown attachment storage %q", cfg.Storage)
+	}
+}
@@ -0,0 +1,170 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+// Exercises the behaviour every backend shares.
+func testStorage(t *testing.T, storage Storage, missingDeleteErr error) {
+	ctx := context.Background()
+	contents := []byte("%PDF-1.4 fake resume")
+
+	err := storage.Put(ctx, "attachments/abc", bytes.NewReader(contents), int64(len(contents)), "application/pdf")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	body, err := storage.Get(ctx, "attachments/abc")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatalf("Failed to read blob: %v", err)
+	}
+	if !bytes.Equal(got, contents) {
+		t.Errorf("Expected %q, got %q", contents, got)
+	}
+
+	_, err = storage.Get(ctx, "attachments/missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing key, got %v", err)
+	}
+
+	err = storage.Delete(ctx, "attachments/abc")
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	_, err = storage.Get(ctx, "attachments/abc")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+
+	err = storage.Delete(ctx, "attachments/abc")
+	if !errors.Is(err, missingDeleteErr) {
+		t.Errorf("Expected %v deleting a missing key, got %v", missingDeleteErr, err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	testStorage(t, local, ErrNotFound)
+
+	err = local.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain")
+	if err == nil {
+		t.Errorf("Expected a key outside the root to be rejected")
+	}
+}
+
+func TestMemory(t *testing.T) {
+	testStorage(t, NewMemory(), ErrNotFound)
+}
+
+// Serves just enough of the S3 API for the client: PUT, GET, HEAD and DELETE of path-style objects.
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (fake *fakeS3) ServeHTTP(response_writer http.ResponseWriter, request *http.Request) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+
+	key := request.URL.Path
+
+	switch request.Method {
+	case http.MethodPut:
+		contents, err := io.ReadAll(request.Body)
+		if err == nil && strings.HasPrefix(request.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			contents, err = decodeAWSChunked(contents)
+		}
+		if err != nil {
+			http.Error(response_writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fake.objects[key] = contents
+		response_writer.Header().Set("ETag", `"fake"`)
+	case http.MethodGet, http.MethodHead:
+		contents, ok := fake.objects[key]
+		if !ok {
+			response_writer.Header().Set("Content-Type", "application/xml")
+			response_writer.WriteHeader(http.StatusNotFound)
+			if request.Method == http.MethodGet {
+				io.WriteString(response_writer, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+		response_writer.Header().Set("ETag", `"fake"`)
+		response_writer.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		http.ServeContent(response_writer, request, "", time.Time{}, bytes.NewReader(contents))
+	case http.MethodDelete:
+		delete(fake.objects, key)
+		response_writer.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(response_writer, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+// Strips the framing from a streaming-signed upload: "<hex size>;chunk-signature=...\r\n<data>\r\n", ending with a zero-size chunk.
+func decodeAWSChunked(body []byte) ([]byte, error) {
+	decoded := []byte{}
+
+	for {
+		header, rest, found := bytes.Cut(body, []byte("\r\n"))
+		if !found {
+			return nil, errors.New("truncated chunk header")
+		}
+
+		size_hex, _, _ := strings.Cut(string(header), ";")
+		size, err := strconv.ParseInt(size_hex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return decoded, nil
+		}
+		if int64(len(rest)) < size+2 {
+			return nil, errors.New("truncated chunk")
+		}
+
+		decoded = append(decoded, rest[:size]...)
+		body = rest[size+2:]
+	}
+}
+
+func TestS3(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}})
+	defer server.Close()
+
+	s3, err := NewS3(config.S3Config{
+		Endpoint:        strings.TrimPrefix(server.URL, "http://"),
+		Bucket:          "attachments",
+		Region:          "us-east-1",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	testStorage(t, s3, nil)
+}
@@ -0,0 +1,95 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stores blobs as files under a directory.
+type Local struct {
+	root string
+}
+
+// Creates `root` if needed.
+func NewLocal(root string) (*Local, error) {
+	err := os.MkdirAll(root, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Local{root: root}, nil
+}
+
+func (local *Local) path(key string) (string, error) {
+	path := filepath.FromSlash(key)
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(local.root, path), nil
+}
+
+// Writes to a temporary file first, so readers never see a partial blob.
+func (local *Local) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := local.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return err
+	}
+
+	temp_file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp_file.Name()) // Fails harmlessly once renamed.
+
+	_, err = io.Copy(temp_file, body)
+	if err != nil {
+		temp_file.Close()
+		return err
+	}
+
+	err = temp_file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp_file.Name(), path)
+}
+
+func (local *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := local.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (local *Local) Delete(ctx context.Context, key string) error {
+	path, err := local.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
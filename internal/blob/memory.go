@@ -0,0 +1,55 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Keeps blobs in memory, for tests.
+type Memory struct {
+	mutex sync.Mutex
+	blobs map[string][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{blobs: map[string][]byte{}}
+}
+
+func (memory *Memory) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	contents, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	memory.blobs[key] = contents
+	return nil
+}
+
+func (memory *Memory) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	contents, ok := memory.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(contents)), nil
+}
+
+func (memory *Memory) Delete(ctx context.Context, key string) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	if _, ok := memory.blobs[key]; !ok {
+		return ErrNotFound
+	}
+
+	delete(memory.blobs, key)
+	return nil
+}
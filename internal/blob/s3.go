@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+// Stores blobs in a bucket on S3 or an S3-compatible service such as MinIO.
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3(cfg config.S3Config) (*S3, error) {
+	bucket_lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		bucket_lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: bucket_lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s3 *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s3.client.PutObject(ctx, s3.bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s3 *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s3.client.GetObject(ctx, s3.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// GetObject is lazy, so check the object exists before the caller starts a response.
+	_, err = object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, translateS3Error(err)
+	}
+
+	return object, nil
+}
+
+// S3 deletes succeed for missing keys, so unlike Local this never returns ErrNotFound.
+func (s3 *S3) Delete(ctx context.Context, key string) error {
+	return s3.client.RemoveObject(ctx, s3.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func translateS3Error(err error) error {
+	if err != nil && minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return errors.Join(ErrNotFound, err)
+	}
+	return err
+}
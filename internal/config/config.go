@@ -0,0 +1,517 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Path used when neither `--config` nor APP_CONFIG is given.
+const DefaultPath string = "configs/config.yaml"
+
+// Full application config, loaded from a .yaml file and then overridden by env vars.
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Database    store.DBConfig    `yaml:"database"`
+	Sessions    SessionConfig     `yaml:"sessions"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Offers      OffersConfig      `yaml:"offers"`
+	Attachments AttachmentsConfig `yaml:"attachments"`
+	Trash       TrashConfig       `yaml:"trash"`
+	Archive     ArchiveConfig     `yaml:"archive"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"` // How long in-flight requests get to finish on shutdown.
+	RequireIfMatch    bool          `yaml:"require_if_match"` // Reject application updates without an If-Match header, with 428.
+}
+
+type SessionConfig struct {
+	Lifetime     time.Duration `yaml:"lifetime"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	CookieSecure bool          `yaml:"cookie_secure"`
+	SameSite     string        `yaml:"same_site"` // One of "lax", "strict" or "none".
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	MaxAge         int      `yaml:"max_age"` // Seconds.
+}
+
+type LoggingConfig struct {
+	Level        string `yaml:"level"`    // Minimum level written to the log file.
+	Encoding     string `yaml:"encoding"` // "console" or "json".
+	Console      bool   `yaml:"console"`  // Also log to stdout.
+	ConsoleLevel string `yaml:"console_level"`
+
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"max_size_mb"`
+	MaxBackups int    `yaml:"max_backups"`
+	MaxAgeDays int    `yaml:"max_age_days"`
+	Compress   bool   `yaml:"compress"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"` // One of "none", "stdout", "file" or "otlp".
+	File        string  `yaml:"file"`     // Used by the "file" exporter.
+	Endpoint    string  `yaml:"endpoint"` // OTLP/HTTP URL, falls back to the OTEL_EXPORTER_OTLP_* env vars.
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"` // Fraction of new traces recorded, from 0 to 1.
+}
+
+// Static exchange rates used to compare offers in different currencies.
+type OffersConfig struct {
+	BaseCurrency  string             `yaml:"base_currency"`  // Offers are compared in this currency unless the request asks for another.
+	ExchangeRates map[string]float64 `yaml:"exchange_rates"` // Units of BaseCurrency per unit of each currency, keyed by ISO 4217 code.
+}
+
+// Converts `amount` between currencies via the base currency.
+func (cfg OffersConfig) Convert(amount float64, from string, to string) (float64, error) {
+	from_rate, ok := cfg.ExchangeRates[strings.ToUpper(from)]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	to_rate, ok := cfg.ExchangeRates[strings.ToUpper(to)]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+
+	return amount * from_rate / to_rate, nil
+}
+
+type AttachmentsConfig struct {
+	MaxSizeMB int64    `yaml:"max_size_mb"` // Largest file accepted per upload.
+	Storage   string   `yaml:"storage"`     // "local" or "s3".
+	LocalDir  string   `yaml:"local_dir"`   // Used by "local" storage.
+	S3        S3Config `yaml:"s3"`
+}
+
+// Connection details for S3 or an S3-compatible service such as MinIO.
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint"` // Host and optional port, without a scheme.
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"-"` // Only read from APP_S3_SECRET_ACCESS_KEY, so it stays out of config files.
+	UseSSL          bool   `yaml:"use_ssl"`
+	PathStyle       bool   `yaml:"path_style"` // Address buckets as /bucket/key, as most S3-compatible services expect.
+}
+
+// How long deleted applications stay restorable before a background job removes them for good.
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval"` // How often the purge runs.
+}
+
+// Rules for archiving applications that have been closed for a while, applied by a background job.
+type ArchiveConfig struct {
+	Interval time.Duration       `yaml:"interval"` // How often the rules are applied.
+	Rules    []ArchiveRuleConfig `yaml:"rules"`
+}
+
+// How long responses to requests with an Idempotency-Key header are kept for replay.
+type IdempotencyConfig struct {
+	TTL           time.Duration `yaml:"ttl"`
+	PurgeInterval time.Duration `yaml:"purge_interval"` // How often expired keys are deleted.
+}
+
+type ArchiveRuleConfig struct {
+	Status string        `yaml:"status"` // Name or number, e.g. "Rejected".
+	After  time.Duration `yaml:"after"`  // How long the application must have had the status.
+}
+
+// Converts the rules for the store.
+func (cfg ArchiveConfig) StoreRules() ([]store.ArchiveRule, error) {
+	rules := make([]store.ArchiveRule, 0, len(cfg.Rules))
+	var errs []error
+
+	for i, rule_config := range cfg.Rules {
+		status, err := store.ParseApplicationStatus(rule_config.Status)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("archive.rules[%d].status %q: %w", i, rule_config.Status, err))
+			continue
+		}
+
+		rule := store.ArchiveRule{Status: status, After: rule_config.After}
+		err = rule.Validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("archive.rules[%d]: %w", i, err))
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, errors.Join(errs...)
+}
+
+// Argon2 parameters used when hashing passwords for newly registered users.
+type AuthConfig struct {
+	Argon2Memory  uint32 `yaml:"argon2_memory"` // KiB.
+	Argon2Time    uint32 `yaml:"argon2_time"`
+	Argon2Threads uint8  `yaml:"argon2_threads"`
+}
+
+// Returns the config used for any value not set in the file or env.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":4000",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		Database: store.DBConfig{
+			Port:           "5432",
+			SSLMode:        "prefer",
+			MigrateOnStart: true,
+		},
+		Sessions: SessionConfig{
+			Lifetime:     12 * time.Hour,
+			IdleTimeout:  1 * time.Hour,
+			CookieSecure: true,
+			SameSite:     "lax",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			MaxAge:         300,
+		},
+		Logging: LoggingConfig{
+			Level:        "info",
+			Encoding:     "console",
+			Console:      true,
+			ConsoleLevel: "warn",
+
+			File:       "./logs/app.log",
+			MaxSizeMB:  10,
+			MaxBackups: 2,
+			MaxAgeDays: 14,
+			Compress:   false,
+		},
+		Auth: AuthConfig{
+			Argon2Memory:  64 * 1024,
+			Argon2Time:    1,
+			Argon2Threads: 4,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			File:        "./logs/traces.jsonl",
+			ServiceName: "application-tracker",
+			SampleRatio: 1,
+		},
+		Offers: OffersConfig{
+			// Rough rates, only meant for ballpark comparisons. Override them in the config file.
+			BaseCurrency: "USD",
+			ExchangeRates: map[string]float64{
+				"USD": 1,
+				"EUR": 1.08,
+				"GBP": 1.27,
+				"CAD": 0.73,
+				"AUD": 0.66,
+				"NZD": 0.60,
+				"CHF": 1.12,
+				"JPY": 0.0067,
+				"INR": 0.012,
+				"SGD": 0.74,
+			},
+		},
+		Attachments: AttachmentsConfig{
+			MaxSizeMB: 10,
+			Storage:   "local",
+			LocalDir:  "./data/attachments",
+			S3: S3Config{
+				Region: "us-east-1",
+				UseSSL: true,
+			},
+		},
+		Trash: TrashConfig{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: 1 * time.Hour,
+		},
+		Archive: ArchiveConfig{
+			Interval: 1 * time.Hour,
+			Rules: []ArchiveRuleConfig{
+				{Status: "Rejected", After: 30 * 24 * time.Hour},
+			},
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           24 * time.Hour,
+			PurgeInterval: 1 * time.Hour,
+		},
+	}
+}
+
+// Loads the .yaml config file from `path` over the defaults, then applies env overrides.
+// An empty `path` skips the file entirely.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		config_file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		err = yaml.Unmarshal(config_file, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	err := cfg.applyEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Overrides config values from env vars. `lookup` is swappable for testing.
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+
+	setString := func(key string, target *string) {
+		if value, ok := lookup(key); ok {
+			*target = value
+		}
+	}
+
+	setBool := func(key string, target *bool) {
+		if value, ok := lookup(key); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	setDuration := func(key string, target *time.Duration) {
+		if value, ok := lookup(key); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	setString("APP_SERVER_ADDR", &cfg.Server.Addr)
+	setDuration("APP_SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	setBool("APP_SERVER_REQUIRE_IF_MATCH", &cfg.Server.RequireIfMatch)
+
+	setString("DATABASE_URL", &cfg.Database.URL)
+	setString("APP_DB_USER", &cfg.Database.User)
+	setString("APP_DB_HOST", &cfg.Database.Host)
+	setString("APP_DB_PORT", &cfg.Database.Port)
+	setString("APP_DB_NAME", &cfg.Database.Name)
+	setString("APP_DB_PASSWORD", &cfg.Database.Password)
+	setString("APP_DB_PASSWORD_FILE", &cfg.Database.PasswordFile)
+	setString("APP_DB_SSLMODE", &cfg.Database.SSLMode)
+	setBool("APP_DB_MIGRATE_ON_START", &cfg.Database.MigrateOnStart)
+
+	setDuration("APP_SESSION_LIFETIME", &cfg.Sessions.Lifetime)
+	setDuration("APP_SESSION_IDLE_TIMEOUT", &cfg.Sessions.IdleTimeout)
+	setBool("APP_SESSION_COOKIE_SECURE", &cfg.Sessions.CookieSecure)
+	setString("APP_SESSION_SAME_SITE", &cfg.Sessions.SameSite)
+
+	if value, ok := lookup("APP_CORS_ALLOWED_ORIGINS"); ok {
+		cfg.CORS.AllowedOrigins = splitList(value)
+	}
+
+	setString("APP_LOG_LEVEL", &cfg.Logging.Level)
+	setString("APP_LOG_ENCODING", &cfg.Logging.Encoding)
+	setBool("APP_LOG_CONSOLE", &cfg.Logging.Console)
+	setString("APP_LOG_CONSOLE_LEVEL", &cfg.Logging.ConsoleLevel)
+	setString("APP_LOG_FILE", &cfg.Logging.File)
+
+	setString("APP_TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	setString("APP_TRACING_FILE", &cfg.Tracing.File)
+	setString("APP_TRACING_ENDPOINT", &cfg.Tracing.Endpoint)
+
+	setString("APP_OFFERS_BASE_CURRENCY", &cfg.Offers.BaseCurrency)
+
+	setString("APP_ATTACHMENTS_STORAGE", &cfg.Attachments.Storage)
+	setString("APP_ATTACHMENTS_LOCAL_DIR", &cfg.Attachments.LocalDir)
+	setString("APP_S3_ENDPOINT", &cfg.Attachments.S3.Endpoint)
+	setString("APP_S3_BUCKET", &cfg.Attachments.S3.Bucket)
+	setString("APP_S3_REGION", &cfg.Attachments.S3.Region)
+	setString("APP_S3_ACCESS_KEY_ID", &cfg.Attachments.S3.AccessKeyID)
+	setString("APP_S3_SECRET_ACCESS_KEY", &cfg.Attachments.S3.SecretAccessKey)
+	setBool("APP_S3_USE_SSL", &cfg.Attachments.S3.UseSSL)
+
+	setDuration("APP_TRASH_RETENTION", &cfg.Trash.Retention)
+	setDuration("APP_TRASH_PURGE_INTERVAL", &cfg.Trash.PurgeInterval)
+
+	setDuration("APP_ARCHIVE_INTERVAL", &cfg.Archive.Interval)
+
+	setDuration("APP_IDEMPOTENCY_TTL", &cfg.Idempotency.TTL)
+	setDuration("APP_IDEMPOTENCY_PURGE_INTERVAL", &cfg.Idempotency.PurgeInterval)
+
+	return errors.Join(errs...)
+}
+
+// Checks the whole config, returning every problem found rather than just the first.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	if cfg.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr is required"))
+	}
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout must be positive"))
+	}
+
+	if err := cfg.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Sessions.Lifetime <= 0 {
+		errs = append(errs, errors.New("sessions.lifetime must be positive"))
+	}
+	if cfg.Sessions.IdleTimeout < 0 {
+		errs = append(errs, errors.New("sessions.idle_timeout must not be negative"))
+	}
+	same_site, err := cfg.Sessions.SameSiteMode()
+	if err != nil {
+		errs = append(errs, err)
+	} else if same_site == http.SameSiteNoneMode && !cfg.Sessions.CookieSecure {
+		errs = append(errs, errors.New("sessions.same_site \"none\" requires sessions.cookie_secure"))
+	}
+
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if origin == "*" {
+			errs = append(errs, errors.New("cors.allowed_origins cannot contain \"*\" as credentials are allowed"))
+		}
+	}
+	if cfg.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.max_age must not be negative"))
+	}
+
+	if _, err := zapcore.ParseLevel(cfg.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	if _, err := zapcore.ParseLevel(cfg.Logging.ConsoleLevel); cfg.Logging.Console && err != nil {
+		errs = append(errs, fmt.Errorf("logging.console_level: %w", err))
+	}
+	if cfg.Logging.Encoding != "console" && cfg.Logging.Encoding != "json" {
+		errs = append(errs, fmt.Errorf("logging.encoding %q is not one of console or json", cfg.Logging.Encoding))
+	}
+	if cfg.Logging.File == "" {
+		errs = append(errs, errors.New("logging.file is required"))
+	}
+	if cfg.Logging.MaxSizeMB <= 0 {
+		errs = append(errs, errors.New("logging.max_size_mb must be positive"))
+	}
+
+	if cfg.Auth.Argon2Memory == 0 || cfg.Auth.Argon2Time == 0 || cfg.Auth.Argon2Threads == 0 {
+		errs = append(errs, errors.New("auth.argon2_memory, auth.argon2_time and auth.argon2_threads must be positive"))
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	case "file":
+		if cfg.Tracing.File == "" {
+			errs = append(errs, errors.New("tracing.file is required by the file exporter"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter %q is not one of none, stdout, file or otlp", cfg.Tracing.Exporter))
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	if _, ok := cfg.Offers.ExchangeRates[cfg.Offers.BaseCurrency]; !ok {
+		errs = append(errs, fmt.Errorf("offers.exchange_rates has no rate for offers.base_currency %q", cfg.Offers.BaseCurrency))
+	}
+	for currency, rate := range cfg.Offers.ExchangeRates {
+		if rate <= 0 {
+			errs = append(errs, fmt.Errorf("offers.exchange_rates.%s must be positive", currency))
+		}
+		if currency != strings.ToUpper(currency) {
+			errs = append(errs, fmt.Errorf("offers.exchange_rates.%s must be an upper case ISO 4217 code", currency))
+		}
+	}
+
+	if cfg.Attachments.MaxSizeMB <= 0 {
+		errs = append(errs, errors.New("attachments.max_size_mb must be positive"))
+	}
+	switch cfg.Attachments.Storage {
+	case "local":
+		if cfg.Attachments.LocalDir == "" {
+			errs = append(errs, errors.New("attachments.local_dir is required by local storage"))
+		}
+	case "s3":
+		if cfg.Attachments.S3.Endpoint == "" || cfg.Attachments.S3.Bucket == "" {
+			errs = append(errs, errors.New("attachments.s3.endpoint and attachments.s3.bucket are required by s3 storage"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("attachments.storage %q is not one of local or s3", cfg.Attachments.Storage))
+	}
+
+	if cfg.Trash.Retention <= 0 || cfg.Trash.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("trash.retention and trash.purge_interval must be positive"))
+	}
+
+	if cfg.Archive.Interval <= 0 {
+		errs = append(errs, errors.New("archive.interval must be positive"))
+	}
+	if _, err := cfg.Archive.StoreRules(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cfg.Idempotency.TTL <= 0 || cfg.Idempotency.PurgeInterval <= 0 {
+		errs = append(errs, errors.New("idempotency.ttl and idempotency.purge_interval must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (cfg *SessionConfig) SameSiteMode() (http.SameSite, error) {
+	switch strings.ToLower(cfg.SameSite) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return http.SameSiteDefaultMode, fmt.Errorf("sessions.same_site %q is not one of lax, strict or none", cfg.SameSite)
+	}
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(contents), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  addr: ":8080"
+database:
+  user: tracker
+  host: localhost
+  port: 5433
+  name: tracker
+sessions:
+  lifetime: 2h
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":8080" {
+		t.Errorf("Expected server.addr %q, got %q", ":8080", cfg.Server.Addr)
+	}
+	if cfg.Database.Port != "5433" {
+		t.Errorf("Expected database.port %q, got %q", "5433", cfg.Database.Port)
+	}
+	if cfg.Sessions.Lifetime != 2*time.Hour {
+		t.Errorf("Expected sessions.lifetime 2h, got %v", cfg.Sessions.Lifetime)
+	}
+	// Values missing from the file keep their defaults.
+	if cfg.Sessions.IdleTimeout != time.Hour {
+		t.Errorf("Expected default sessions.idle_timeout 1h, got %v", cfg.Sessions.IdleTimeout)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+}
+
+func TestLoadMissingConfigFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Fatalf("Failed to throw error on missing config file")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"APP_SERVER_ADDR":           ":9000",
+		"DATABASE_URL":              "postgresql://localhost/tracker",
+		"APP_SESSION_COOKIE_SECURE": "false",
+		"APP_CORS_ALLOWED_ORIGINS":  "https://a.example, https://b.example",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	cfg := Default()
+	err := cfg.applyEnv(lookup)
+	if err != nil {
+		t.Fatalf("applyEnv() error: %v", err)
+	}
+
+	if cfg.Server.Addr != ":9000" {
+		t.Errorf("Expected server.addr %q, got %q", ":9000", cfg.Server.Addr)
+	}
+	if cfg.Database.URL != "postgresql://localhost/tracker" {
+		t.Errorf("Expected database.url from DATABASE_URL, got %q", cfg.Database.URL)
+	}
+	if cfg.Sessions.CookieSecure {
+		t.Errorf("Expected sessions.cookie_secure false")
+	}
+	if len(cfg.CORS.AllowedOrigins) != 2 || cfg.CORS.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("Unexpected cors.allowed_origins: %v", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		if key == "APP_SESSION_LIFETIME" {
+			return "forever", true
+		}
+		return "", false
+	}
+
+	cfg := Default()
+	err := cfg.applyEnv(lookup)
+	if err == nil || !strings.Contains(err.Error(), "APP_SESSION_LIFETIME") {
+		t.Fatalf("Expected error naming APP_SESSION_LIFETIME, got %v", err)
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Addr = ""
+	cfg.Sessions.SameSite = "sometimes"
+	cfg.CORS.AllowedOrigins = []string{"*"}
+	cfg.Trash.Retention = 0
+	cfg.Archive.Rules = []ArchiveRuleConfig{{Status: "Ghosted", After: time.Hour}}
+	cfg.Idempotency.TTL = 0
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("Failed to throw error on invalid config")
+	}
+
+	for _, expected := range []string{"server.addr", "database.user", "sessions.same_site", "cors.allowed_origins", "trash.retention", "archive.rules[0].status", "idempotency.ttl"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to mention %q, got: %v", expected, err)
+		}
+	}
+}
+
+func TestLoadExchangeRatesMergeWithDefaults(t *testing.T) {
+	path := writeConfigFile(t, `
+offers:
+  base_currency: GBP
+  exchange_rates:
+    GBP: 1
+    USD: 0.8
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Offers.ExchangeRates["USD"] != 0.8 {
+		t.Errorf("Expected the file to override the USD rate, got %v", cfg.Offers.ExchangeRates["USD"])
+	}
+	if _, ok := cfg.Offers.ExchangeRates["JPY"]; !ok {
+		t.Errorf("Expected default rates to be kept")
+	}
+
+	converted, err := cfg.Offers.Convert(100, "USD", "GBP")
+	if err != nil || converted != 80 {
+		t.Errorf("Expected 100 USD to be 80 GBP, got %v (%v)", converted, err)
+	}
+
+	_, err = cfg.Offers.Convert(100, "XYZ", "GBP")
+	if err == nil {
+		t.Errorf("Expected an error converting from an unknown currency")
+	}
+}
+
+func TestAttachmentsS3FromEnv(t *testing.T) {
+	env := map[string]string{
+		"APP_ATTACHMENTS_STORAGE":  "s3",
+		"APP_S3_ENDPOINT":          "minio:9000",
+		"APP_S3_BUCKET":            "attachments",
+		"APP_S3_SECRET_ACCESS_KEY": "secret",
+		"APP_S3_USE_SSL":           "false",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	cfg := Default()
+	err := cfg.applyEnv(lookup)
+	if err != nil {
+		t.Fatalf("applyEnv() error: %v", err)
+	}
+
+	if cfg.Attachments.S3.Endpoint != "minio:9000" || cfg.Attachments.S3.SecretAccessKey != "secret" || cfg.Attachments.S3.UseSSL {
+		t.Errorf("Unexpected attachments.s3: %+v", cfg.Attachments.S3)
+	}
+
+	cfg.Attachments.S3.Bucket = ""
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "attachments.s3") {
+		t.Errorf("Expected error to mention attachments.s3, got: %v", err)
+	}
+}
@@ -1,13 +1,53 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/alexedwards/scs/v2"
+	"github.com/medidew/ApplicationTracker/internal/blob"
+	"github.com/medidew/ApplicationTracker/internal/config"
+	"github.com/medidew/ApplicationTracker/internal/logging"
+	"github.com/medidew/ApplicationTracker/internal/metrics"
+	"github.com/medidew/ApplicationTracker/internal/tracing"
 	"github.com/medidew/ApplicationTracker/internal/store"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type App struct {
 	DB     store.Store
+	Blobs  blob.Storage // Attachment contents.
 	Logger *zap.Logger
 	SessionManager *scs.SessionManager
-}
\ No newline at end of file
+	Config *config.Config
+	Metrics *metrics.Metrics
+	TracerProvider trace.TracerProvider
+}
+
+// Returns the request-scoped logger, tagged with the request ID and username, falling back to app.Logger.
+func (app *App) logger(request *http.Request) *zap.Logger {
+	return logging.FromContext(request.Context(), app.Logger)
+}
+
+func (app *App) tracer() trace.Tracer {
+	return app.TracerProvider.Tracer(tracing.TracerName)
+}
+
+// Responds 404 for store.ErrNotFound, 409 for store.ErrConflict, 412 for store.ErrVersionMismatch, otherwise 500 with `message` and the error.
+func writeStoreError(response_writer http.ResponseWriter, message string, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(response_writer, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrConflict) {
+		http.Error(response_writer, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, store.ErrVersionMismatch) {
+		http.Error(response_writer, "application has changed, fetch it again for the current ETag", http.StatusPreconditionFailed)
+		return
+	}
+
+	http.Error(response_writer, message+": "+err.Error(), http.StatusInternalServerError)
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -17,13 +20,21 @@ func (app *App) ListApplications(response_writer http.ResponseWriter, request *h
 		http.Error(response_writer, "No username in session", http.StatusUnauthorized)
 	}
 
-	applications, err := app.DB.ListApplications(username)
+	filter, err := parseApplicationFilter(request)
+	if err != nil {
+		http.Error(response_writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	applications, err := app.DB.ListApplications(request.Context(), username, filter)
 	if err != nil {
 		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	_, marshal_span := app.tracer().Start(request.Context(), "marshal applications")
 	response, err := json.Marshal(applications)
+	marshal_span.End()
 	if err != nil {
 		http.Error(response_writer, "failed to marshal: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -41,11 +52,25 @@ func (app *App) GetApplication(response_writer http.ResponseWriter, request *htt
 	companyID := chi.URLParam(request, "companyID")
 	username := app.SessionManager.GetString(request.Context(), "username")
 
-	job_application, err := app.DB.GetApplication(username, companyID)
+	job_application, err := app.DB.GetApplication(request.Context(), username, companyID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	contacts, err := app.DB.ListApplicationContacts(request.Context(), username, companyID)
 	if err != nil {
 		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	job_application.SetContacts(contacts)
+
+	tags, err := app.DB.ListApplicationTags(request.Context(), username, companyID)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	job_application.SetTags(tags)
 
 	response, err := json.Marshal(job_application)
 	if err != nil {
@@ -53,6 +78,7 @@ func (app *App) GetApplication(response_writer http.ResponseWriter, request *htt
 		return
 	}
 
+	response_writer.Header().Set("ETag", applicationETag(job_application.GetVersion()))
 	_, err = response_writer.Write(response)
 	if err != nil {
 		http.Error(response_writer, "failed to write reponse: "+err.Error(), http.StatusInternalServerError)
@@ -77,22 +103,118 @@ func (app *App) CreateApplication(response_writer http.ResponseWriter, request *
 	}
 
 	username := app.SessionManager.GetString(request.Context(), "username")
-	err = app.DB.CreateApplication(username, new_application)
+	custom_fields, ok := app.validateCustomFieldValues(response_writer, request, username, new_application.GetCustomFields())
+	if !ok {
+		return
+	}
+	new_application.SetCustomFields(custom_fields)
+
+	err = app.DB.CreateApplication(request.Context(), username, new_application)
 	if err != nil {
-		http.Error(response_writer, "DB insert failed: "+err.Error(), http.StatusInternalServerError)
+		writeCreateApplicationError(response_writer, new_application.GetCompany(), err)
 		return
 	}
 
 	response_writer.WriteHeader(http.StatusCreated)
 }
 
+// Like writeStoreError, pointing to the restore route when the company's application is in the trash.
+func writeCreateApplicationError(response_writer http.ResponseWriter, company string, err error) {
+	if errors.Is(err, store.ErrTrashed) {
+		http.Error(response_writer, "the application is in the trash, restore it with POST /applications/"+url.PathEscape(company)+"/restore", http.StatusConflict)
+		return
+	}
+
+	writeStoreError(response_writer, "DB insert failed", err)
+}
+
+// Moves the application to the trash, where it can be restored until the purge removes it.
 func (app *App) DeleteApplication(response_writer http.ResponseWriter, request *http.Request) {
 	companyID := chi.URLParam(request, "companyID")
 	username := app.SessionManager.GetString(request.Context(), "username")
 
-	err := app.DB.DeleteApplication(username, companyID)
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.DB.DeleteApplication(request.Context(), username, companyID, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Hides the application from the pipeline without changing its status.
+func (app *App) ArchiveApplication(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.DB.ArchiveApplication(request.Context(), username, companyID, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Returns an archived application to the pipeline. The auto-archive rules leave it alone until
+// their period has passed again.
+func (app *App) UnarchiveApplication(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.DB.UnarchiveApplication(request.Context(), username, companyID, expected_version)
 	if err != nil {
-		http.Error(response_writer, "DB delete failed: "+err.Error(), http.StatusInternalServerError)
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Lists the user's deleted applications, most recently deleted first, each with its `deleted_at`.
+func (app *App) ListTrashedApplications(response_writer http.ResponseWriter, request *http.Request) {
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	applications, err := app.DB.ListTrashedApplications(request.Context(), username)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, applications)
+}
+
+// Moves a deleted application back out of the trash.
+func (app *App) RestoreApplication(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	err := app.DB.RestoreApplication(request.Context(), username, companyID, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
 		return
 	}
 
@@ -102,6 +224,11 @@ func (app *App) DeleteApplication(response_writer http.ResponseWriter, request *
 func (app *App) UpdateApplicationStatus(response_writer http.ResponseWriter, request *http.Request) {
 	companyID := chi.URLParam(request, "companyID")
 
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
 	var status_update struct {
 		Status store.ApplicationStatus `json:"status"`
 	}
@@ -116,9 +243,83 @@ func (app *App) UpdateApplicationStatus(response_writer http.ResponseWriter, req
 	}
 
 	username := app.SessionManager.GetString(request.Context(), "username")
-	err = app.DB.UpdateApplicationStatus(username, companyID, status_update.Status)
+	err = app.DB.UpdateApplicationStatus(request.Context(), username, companyID, status_update.Status, expected_version)
 	if err != nil {
-		http.Error(response_writer, "DB update failed: "+err.Error(), http.StatusInternalServerError)
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Schedules a follow-up reminder for the application, or clears it with `{"follow_up_at": null}`.
+func (app *App) SetApplicationFollowUp(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	var follow_up struct {
+		FollowUpAt *time.Time `json:"follow_up_at"`
+	}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&follow_up)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	follow_up_at := time.Time{}
+	if follow_up.FollowUpAt != nil {
+		follow_up_at = *follow_up.FollowUpAt
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.SetApplicationFollowUp(request.Context(), username, companyID, follow_up_at, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Replaces the application's salary, location, remote policy, employment type and source.
+// Fields left out of the body are cleared.
+func (app *App) UpdateApplicationDetails(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	var details store.ApplicationDetails
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&details)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = details.Validate()
+	if err != nil {
+		http.Error(response_writer, "invalid details: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateApplicationDetails(request.Context(), username, companyID, details, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
 		return
 	}
 
@@ -129,7 +330,7 @@ func (app *App) ListApplicationNotes(response_writer http.ResponseWriter, reques
 	companyID := chi.URLParam(request, "companyID")
 	username := app.SessionManager.GetString(request.Context(), "username")
 
-	notes, err := app.DB.ListApplicationNotes(username, companyID)
+	notes, err := app.DB.ListApplicationNotes(request.Context(), username, companyID)
 	if err != nil {
 		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -164,10 +365,15 @@ func (app *App) AddApplicationNote(response_writer http.ResponseWriter, request
 		return
 	}
 
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
 	username := app.SessionManager.GetString(request.Context(), "username")
-	err = app.DB.AddApplicationNote(username, companyID, note_addition.Note)
+	err = app.DB.AddApplicationNote(request.Context(), username, companyID, note_addition.Note, expected_version)
 	if err != nil {
-		http.Error(response_writer, "DB update failed: "+err.Error(), http.StatusInternalServerError)
+		writeStoreError(response_writer, "DB update failed", err)
 		return
 	}
 
@@ -182,10 +388,15 @@ func (app *App) RemoveApplicationNote(response_writer http.ResponseWriter, reque
 		return
 	}
 
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
 	username := app.SessionManager.GetString(request.Context(), "username")
-	err = app.DB.RemoveApplicationNote(username, companyID, noteIndex)
+	err = app.DB.RemoveApplicationNote(request.Context(), username, companyID, noteIndex, expected_version)
 	if err != nil {
-		http.Error(response_writer, "DB update failed: "+err.Error(), http.StatusInternalServerError)
+		writeStoreError(response_writer, "DB update failed", err)
 		return
 	}
 
@@ -6,12 +6,18 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/medidew/ApplicationTracker/internal/blob"
+	"github.com/medidew/ApplicationTracker/internal/config"
+	"github.com/medidew/ApplicationTracker/internal/metrics"
 	"github.com/medidew/ApplicationTracker/internal/store"
 )
 
@@ -41,8 +47,12 @@ func setupTestApp() *App {
 
 	return &App{
 		DB:     database,
+		Blobs:  blob.NewMemory(),
 		Logger: zap.NewNop(),
 		SessionManager: session_manager,
+		Config: config.Default(),
+		Metrics: metrics.New(),
+		TracerProvider: noop.NewTracerProvider(),
 	}
 }
 
@@ -130,8 +140,8 @@ func TestGetInvalidApplication(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.StatusCode)
 	}
 }
 
@@ -164,6 +174,28 @@ func TestCreateApplication(t *testing.T) {
 	}
 }
 
+func TestCreateApplicationEmptyCompany(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	for _, company := range []string{"", "   "} {
+		status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications", `{"company": "`+company+`", "role": "Software Engineer", "status": 0, "notes": []}`)
+		if status != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for company %q, got %d: %s", http.StatusBadRequest, company, status, body)
+		}
+	}
+
+	applications, err := app.DB.ListApplications(t.Context(), "testuser", store.ApplicationFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list applications: %v", err)
+	}
+	if len(applications) != 2 {
+		t.Errorf("Expected no application to be created, got %d applications", len(applications))
+	}
+}
+
 func TestCreateDuplicateApplication(t *testing.T) {
 	app := setupTestApp()
 	router := setupTestRouter(app)
@@ -188,8 +220,8 @@ func TestCreateDuplicateApplication(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected status code %d, got %d", http.StatusConflict, response.StatusCode)
 	}
 }
 
@@ -215,6 +247,276 @@ func TestDeleteApplication(t *testing.T) {
 	}
 }
 
+func TestArchiveApplication(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/archive", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	tests := []struct {
+		url          string
+		archived     bool
+		not_archived bool
+	}{
+		{"/applications", false, true},
+		{"/applications?archived=false", false, true},
+		{"/applications?archived=true", true, false},
+		{"/applications?archived=all", true, true},
+	}
+
+	for _, test := range tests {
+		status, body := sendRequest(t, app, router, token, http.MethodGet, test.url, "")
+		if status != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, got %d", test.url, http.StatusOK, status)
+		}
+		if strings.Contains(body, `"company":"Fake Company"`) != test.archived {
+			t.Errorf("%s: expected the archived application listed to be %v, got %s", test.url, test.archived, body)
+		}
+		if strings.Contains(body, `"company":"Another Fake Company"`) != test.not_archived {
+			t.Errorf("%s: expected the live application listed to be %v, got %s", test.url, test.not_archived, body)
+		}
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/applications?archived=maybe", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid archived filter, got %d", http.StatusBadRequest, status)
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"status":"Active"`) || strings.Contains(body, `"archived_at":null`) {
+		t.Errorf("Expected the archived application to keep its status, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/unarchive", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	_, body = sendRequest(t, app, router, token, http.MethodGet, "/applications", "")
+	if !strings.Contains(body, `"company":"Fake Company"`) {
+		t.Errorf("Expected the unarchived application back in the list, got %s", body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Nobody/archive", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d archiving a missing application, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestArchiveStaleApplications(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPut, "/applications/Another%20Fake%20Company", `{"status": 2}`)
+
+	rules := []store.ArchiveRule{{Status: store.Rejected, After: time.Hour}}
+
+	archived, err := app.DB.ArchiveStaleApplications(t.Context(), rules, time.Now())
+	if err != nil || archived != 0 {
+		t.Fatalf("Expected nothing archived within the period, got %d, %v", archived, err)
+	}
+
+	archived, err = app.DB.ArchiveStaleApplications(t.Context(), rules, time.Now().Add(2*time.Hour))
+	if err != nil || archived != 1 {
+		t.Fatalf("Expected the rejection archived, got %d, %v", archived, err)
+	}
+
+	_, body := sendRequest(t, app, router, token, http.MethodGet, "/applications?archived=true", "")
+	if !strings.Contains(body, `"company":"Another Fake Company"`) || strings.Contains(body, `"company":"Fake Company"`) {
+		t.Errorf("Expected only the rejection archived, got %s", body)
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/unarchive", "")
+
+	archived, err = app.DB.ArchiveStaleApplications(t.Context(), rules, time.Now().Add(30*time.Minute))
+	if err != nil || archived != 0 {
+		t.Errorf("Expected unarchiving to restart the period, got %d, %v", archived, err)
+	}
+}
+
+func TestTrashAndRestoreApplication(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	_, body := sendRequest(t, app, router, token, http.MethodGet, "/applications", "")
+	if strings.Contains(body, `"company":"Fake Company"`) {
+		t.Errorf("Expected the trashed application to be hidden from the list, got %s", body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d getting a trashed application, got %d", http.StatusNotFound, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/trash", "")
+	if status != http.StatusOK || !strings.Contains(body, `"company":"Fake Company"`) || !strings.Contains(body, `"deleted_at":`) {
+		t.Errorf("Expected the application in the trash, got %d: %s", status, body)
+	}
+
+	// The company can't be reused while it's in the trash, only restored.
+	status, body = sendRequest(t, app, router, token, http.MethodPost, "/applications", `{"company": "Fake Company", "role": "Software Engineer", "status": 0, "notes": []}`)
+	if status != http.StatusConflict || !strings.Contains(body, "/applications/Fake%20Company/restore") {
+		t.Errorf("Expected status code %d pointing to restore, got %d: %s", http.StatusConflict, status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/restore", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusOK || strings.Contains(body, `"deleted_at"`) || !strings.Contains(body, "Note two.") {
+		t.Errorf("Expected the restored application with its notes, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/restore", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d restoring a live application, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestTrashHidesOffersAndAttachments(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/offers", `{"currency": "USD", "base_salary": 110000}`)
+	attachment := &store.Attachment{Company: "Fake Company", Kind: store.Resume, Filename: "cv.pdf", StorageKey: "trashed-key"}
+	err = app.DB.CreateAttachment(t.Context(), "testuser", attachment)
+	if err != nil {
+		t.Fatalf("Failed to create attachment: %v", err)
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	_, body := sendRequest(t, app, router, token, http.MethodGet, "/offers/compare", "")
+	if strings.Contains(body, "Fake Company") {
+		t.Errorf("Expected the trashed application's offer to be hidden from the comparison, got %s", body)
+	}
+
+	_, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/attachments", "")
+	if strings.Contains(body, "cv.pdf") {
+		t.Errorf("Expected the trashed application's attachments to be hidden, got %s", body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/attachments/"+strconv.FormatInt(attachment.ID, 10), "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d downloading a trashed application's attachment, got %d", http.StatusNotFound, status)
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/restore", "")
+	_, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/attachments", "")
+	if !strings.Contains(body, "cv.pdf") {
+		t.Errorf("Expected the attachments back after restoring, got %s", body)
+	}
+}
+
+func TestTrashBlocksInterviewsAndOffers(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	interview := &store.Interview{Company: "Fake Company", Type: store.PhoneScreen, ScheduledAt: time.Date(2030, 1, 15, 15, 0, 0, 0, time.UTC)}
+	err = app.DB.CreateInterview(t.Context(), "testuser", interview, false)
+	if err != nil {
+		t.Fatalf("Failed to create interview: %v", err)
+	}
+	offer := &store.JobOffer{Company: "Fake Company", Currency: "USD", BaseSalary: 110000}
+	err = app.DB.CreateOffer(t.Context(), "testuser", offer)
+	if err != nil {
+		t.Fatalf("Failed to create offer: %v", err)
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	interview_url := "/applications/Fake%20Company/interviews/" + strconv.FormatInt(interview.ID, 10)
+	offer_url := "/applications/Fake%20Company/offers/" + strconv.FormatInt(offer.ID, 10)
+
+	tests := []struct {
+		method string
+		url    string
+		body   string
+	}{
+		{http.MethodPost, "/applications/Fake%20Company/interviews", `{"type": "phone_screen", "scheduled_at": "2030-01-15T15:00:00Z"}`},
+		{http.MethodGet, interview_url, ""},
+		{http.MethodPut, interview_url, `{"type": "phone_screen", "scheduled_at": "2030-01-15T15:00:00Z"}`},
+		{http.MethodDelete, interview_url, ""},
+		{http.MethodPost, "/applications/Fake%20Company/offers", `{"currency": "USD", "base_salary": 120000}`},
+		{http.MethodPut, offer_url, `{"currency": "USD", "base_salary": 120000}`},
+		{http.MethodDelete, offer_url, ""},
+	}
+
+	for _, test := range tests {
+		status, body := sendRequest(t, app, router, token, test.method, test.url, test.body)
+		if status != http.StatusNotFound {
+			t.Errorf("Expected status code %d for %s %s on a trashed application, got %d: %s", http.StatusNotFound, test.method, test.url, status, body)
+		}
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/restore", "")
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, interview_url, "")
+	if status != http.StatusOK {
+		t.Errorf("Expected the interview back after restoring, got %d", status)
+	}
+}
+
+func TestPurgeDeletedApplications(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	attachment := &store.Attachment{Company: "Fake Company", Kind: store.Resume, Filename: "cv.pdf", StorageKey: "purged-key"}
+	err = app.DB.CreateAttachment(t.Context(), "testuser", attachment)
+	if err != nil {
+		t.Fatalf("Failed to create attachment: %v", err)
+	}
+
+	sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company", "")
+
+	purged, storage_keys, err := app.DB.PurgeDeletedApplications(t.Context(), time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Fatalf("Expected nothing purged within the retention period, got %d, %v", purged, err)
+	}
+
+	purged, storage_keys, err = app.DB.PurgeDeletedApplications(t.Context(), time.Now().Add(time.Second))
+	if err != nil || purged != 1 || len(storage_keys) != 1 || storage_keys[0] != "purged-key" {
+		t.Fatalf("Expected the application and its attachment key purged, got %d, %v, %v", purged, storage_keys, err)
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/restore", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d restoring a purged application, got %d", http.StatusNotFound, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications", `{"company": "Fake Company", "role": "Software Engineer"}`)
+	if status != http.StatusCreated {
+		t.Errorf("Expected the company to be free again after the purge, got %d", status)
+	}
+}
+
 func TestDeleteInvalidApplication(t *testing.T) {
 	app := setupTestApp()
 	router := setupTestRouter(app)
@@ -232,8 +534,8 @@ func TestDeleteInvalidApplication(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.StatusCode)
 	}
 }
 
@@ -284,8 +586,8 @@ func TestUpdateInvalidApplicationStatus(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.StatusCode)
 	}
 }
 
@@ -384,8 +686,8 @@ func TestAddApplicationNoteInvalidApplication(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.StatusCode)
 	}
 }
 
@@ -454,8 +756,8 @@ func TestRemoveApplicationNoteInvalidApplication(t *testing.T) {
 	response := response_recorder.Result()
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusInternalServerError {
-		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.StatusCode)
 	}
 }
 
@@ -501,4 +803,79 @@ func TestRemoveApplicationNoteNegativeIndex(t *testing.T) {
 	if response.StatusCode != http.StatusInternalServerError {
 		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
 	}
-}
\ No newline at end of file
+}
+func TestApplicationDetails(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications", `{
+		"company": "Detailed Company",
+		"role": "Software Engineer",
+		"salary": {"min": 60000, "max": 80000, "currency": "gbp"},
+		"location": "Manchester",
+		"remote_policy": "hybrid",
+		"employment_type": "full_time",
+		"source": "referral"
+	}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Detailed%20Company", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, body)
+	}
+	for _, expected := range []string{`"salary":{"min":60000,"max":80000,"currency":"GBP","period":"year"}`, `"remote_policy":"hybrid"`, `"source":"referral"`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %s in %s", expected, body)
+		}
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications?remote_policy=hybrid&remote_policy=remote&salary_currency=GBP&min_salary=75000", "")
+	if status != http.StatusOK || !strings.Contains(body, "Detailed Company") || strings.Contains(body, `"Fake Company"`) {
+		t.Errorf("Unexpected filtered list %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company/details", `{"remote_policy": "remote", "employment_type": "contract"}`)
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications?employment_type=contract", "")
+	if status != http.StatusOK || !strings.Contains(body, `"Fake Company"`) || strings.Contains(body, "Detailed Company") {
+		t.Errorf("Unexpected filtered list %d: %s", status, body)
+	}
+}
+
+func TestApplicationDetailsInvalid(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		expected int
+	}{
+		{"unknown remote policy", http.MethodPost, "/applications", `{"company": "X", "role": "Software Engineer", "remote_policy": "sometimes"}`, http.StatusBadRequest},
+		{"inverted salary", http.MethodPut, "/applications/Fake%20Company/details", `{"salary": {"min": 9, "max": 1, "currency": "USD"}}`, http.StatusBadRequest},
+		{"unknown source", http.MethodPut, "/applications/Fake%20Company/details", `{"source": "carrier pigeon"}`, http.StatusBadRequest},
+		{"missing application", http.MethodPut, "/applications/Nobody/details", `{"location": "Paris"}`, http.StatusNotFound},
+		{"invalid min_salary", http.MethodGet, "/applications?salary_currency=USD&min_salary=lots", "", http.StatusBadRequest},
+		{"min_salary without a currency", http.MethodGet, "/applications?min_salary=75000", "", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := sendRequest(t, app, router, token, test.method, test.url, test.body)
+			if status != test.expected {
+				t.Errorf("Expected status code %d, got %d: %s", test.expected, status, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const docxContentType string = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Room left in the request body for the multipart framing and the `kind` field.
+const multipartOverheadBytes int64 = 1 << 16
+
+// Content types accepted for attachments, as reported by http.DetectContentType.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"text/plain":      true,
+	"image/png":       true,
+	"image/jpeg":      true,
+}
+
+func attachmentIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "attachmentID"), 10, 64)
+}
+
+// Sniffs the content type from the start of the file. A .docx is a zip, so it's only accepted by name.
+// Returns "" if the type isn't allowed.
+func attachmentContentType(head []byte, filename string) string {
+	content_type, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	if content_type == "application/zip" && strings.EqualFold(filepath.Ext(filename), ".docx") {
+		return docxContentType
+	}
+	if !allowedAttachmentTypes[content_type] {
+		return ""
+	}
+
+	return content_type
+}
+
+func newStorageKey() string {
+	random := make([]byte, 16)
+	rand.Read(random)
+	return "attachments/" + hex.EncodeToString(random)
+}
+
+func (app *App) ListAttachments(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	attachments, err := app.DB.ListAttachments(request.Context(), username, companyID)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, attachments)
+}
+
+// Accepts a multipart form with a `file` part and an optional `kind` field. The file is spooled to disk
+// while it's hashed, so its size is known before it's handed to blob storage.
+func (app *App) UploadAttachment(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+	max_bytes := app.Config.Attachments.MaxSizeMB << 20
+
+	request.Body = http.MaxBytesReader(response_writer, request.Body, max_bytes+multipartOverheadBytes)
+
+	reader, err := request.MultipartReader()
+	if err != nil {
+		http.Error(response_writer, "expected a multipart form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attachment := &store.Attachment{Company: companyID, Kind: store.OtherAttachment}
+
+	spool, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		http.Error(response_writer, "failed to create temp file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	hash := sha256.New()
+	have_file := false
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writeUploadError(response_writer, "failed to read multipart form", err)
+			return
+		}
+
+		switch part.FormName() {
+		case "kind":
+			kind, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				writeUploadError(response_writer, "failed to read kind", err)
+				return
+			}
+			attachment.Kind = store.AttachmentKind(kind)
+		case "file":
+			if have_file {
+				http.Error(response_writer, "only one file may be uploaded at a time", http.StatusBadRequest)
+				return
+			}
+			have_file = true
+			attachment.Filename = filepath.Base(part.FileName())
+
+			// Read one byte past the limit so an oversized file can be told apart from one exactly at it.
+			size, err := io.Copy(io.MultiWriter(spool, hash), io.LimitReader(part, max_bytes+1))
+			if err != nil {
+				writeUploadError(response_writer, "failed to read file", err)
+				return
+			}
+			if size > max_bytes {
+				http.Error(response_writer, "file exceeds "+strconv.FormatInt(app.Config.Attachments.MaxSizeMB, 10)+"MB", http.StatusRequestEntityTooLarge)
+				return
+			}
+			attachment.SizeBytes = size
+		}
+		part.Close()
+	}
+
+	if !have_file {
+		http.Error(response_writer, "missing `file` part", http.StatusBadRequest)
+		return
+	}
+	if attachment.Filename == "" || attachment.Filename == "." || attachment.Filename == string(filepath.Separator) {
+		http.Error(response_writer, "missing filename", http.StatusBadRequest)
+		return
+	}
+
+	err = attachment.Kind.Validate()
+	if err != nil {
+		http.Error(response_writer, "invalid attachment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	head := make([]byte, 512)
+	n, err := spool.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(response_writer, "failed to read temp file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	attachment.ContentType = attachmentContentType(head[:n], attachment.Filename)
+	if attachment.ContentType == "" {
+		http.Error(response_writer, "unsupported file type; upload a PDF, DOCX, text file or image", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	attachment.StorageKey = newStorageKey()
+
+	_, err = spool.Seek(0, io.SeekStart)
+	if err != nil {
+		http.Error(response_writer, "failed to rewind temp file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = app.Blobs.Put(request.Context(), attachment.StorageKey, spool, attachment.SizeBytes, attachment.ContentType)
+	if err != nil {
+		http.Error(response_writer, "failed to store file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.CreateAttachment(request.Context(), username, attachment)
+	if err != nil {
+		delete_err := app.Blobs.Delete(request.Context(), attachment.StorageKey)
+		if delete_err != nil {
+			app.logger(request).Warn("Failed to remove orphaned attachment", zap.String("key", attachment.StorageKey), zap.Error(delete_err))
+		}
+
+		writeStoreError(response_writer, "DB insert failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, attachment)
+}
+
+// Responds 413 if the request body went over the limit, otherwise 400.
+func writeUploadError(response_writer http.ResponseWriter, message string, err error) {
+	var max_bytes_err *http.MaxBytesError
+	if errors.As(err, &max_bytes_err) {
+		http.Error(response_writer, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	http.Error(response_writer, message+": "+err.Error(), http.StatusBadRequest)
+}
+
+// Streams the file back with its stored type, and its checksum in a Repr-Digest header (RFC 9530).
+func (app *App) DownloadAttachment(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	attachmentID, err := attachmentIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid attachment ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	attachment, err := app.DB.GetAttachment(request.Context(), username, companyID, attachmentID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	body, err := app.Blobs.Get(request.Context(), attachment.StorageKey)
+	if err != nil {
+		http.Error(response_writer, "failed to read file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	digest, err := hex.DecodeString(attachment.SHA256)
+	if err == nil {
+		response_writer.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(digest)+":")
+	}
+
+	response_writer.Header().Set("Content-Type", attachment.ContentType)
+	response_writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	response_writer.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	response_writer.Header().Set("X-Content-Type-Options", "nosniff")
+	response_writer.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(response_writer, body)
+	if err != nil {
+		app.logger(request).Warn("Failed to stream attachment", zap.Int64("attachment_id", attachmentID), zap.Error(err))
+	}
+}
+
+// Deletes the metadata first, so a failed blob delete leaves an orphaned file rather than a dangling record.
+func (app *App) DeleteAttachment(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	attachmentID, err := attachmentIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid attachment ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	attachment, err := app.DB.GetAttachment(request.Context(), username, companyID, attachmentID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	err = app.DB.DeleteAttachment(request.Context(), username, companyID, attachmentID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	err = app.Blobs.Delete(request.Context(), attachment.StorageKey)
+	if err != nil {
+		app.logger(request).Warn("Failed to remove attachment file", zap.String("key", attachment.StorageKey), zap.Error(err))
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+
+func uploadAttachment(t *testing.T, app *App, router http.Handler, token string, url string, kind string, filename string, contents []byte) *http.Response {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if kind != "" {
+		writer.WriteField("kind", kind)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(contents)
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, url, body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	return response_recorder.Result()
+}
+
+func TestAttachmentRoundTrip(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	response := uploadAttachment(t, app, router, token, "/applications/Fake%20Company/attachments", "resume", "My CV.pdf", testPDF)
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		response_body, _ := io.ReadAll(response.Body)
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, response.StatusCode, response_body)
+	}
+
+	var attachment store.Attachment
+	err = json.NewDecoder(response.Body).Decode(&attachment)
+	if err != nil {
+		t.Fatalf("Failed to decode attachment: %v", err)
+	}
+
+	sum := sha256.Sum256(testPDF)
+	if attachment.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected checksum %x, got %s", sum, attachment.SHA256)
+	}
+	if attachment.ContentType != "application/pdf" || attachment.Kind != store.Resume || attachment.SizeBytes != int64(len(testPDF)) {
+		t.Errorf("Unexpected attachment %+v", attachment)
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/attachments", "")
+	if status != http.StatusOK || !strings.Contains(body, `"filename":"My CV.pdf"`) || strings.Contains(body, "storage_key") {
+		t.Errorf("Unexpected list response %d: %s", status, body)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/applications/Fake%20Company/attachments/1", nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+	router.ServeHTTP(response_recorder, request)
+
+	if response_recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response_recorder.Code)
+	}
+	if !bytes.Equal(response_recorder.Body.Bytes(), testPDF) {
+		t.Errorf("Downloaded contents differ from the upload")
+	}
+	if got := response_recorder.Header().Get("Repr-Digest"); got != "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":" {
+		t.Errorf("Unexpected Repr-Digest %q", got)
+	}
+	if got := response_recorder.Header().Get("Content-Disposition"); got != `attachment; filename="My CV.pdf"` {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company/attachments/1", "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/attachments/1", "")
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d after delete, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestUploadAttachmentRejections(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	app.Config.Attachments.MaxSizeMB = 1
+
+	tests := []struct {
+		name     string
+		url      string
+		kind     string
+		filename string
+		contents []byte
+		expected int
+	}{
+		{"too large", "/applications/Fake%20Company/attachments", "", "big.txt", bytes.Repeat([]byte("a"), 1<<20+1), http.StatusRequestEntityTooLarge},
+		{"at the limit", "/applications/Fake%20Company/attachments", "", "big.txt", bytes.Repeat([]byte("a"), 1<<20), http.StatusCreated},
+		{"executable", "/applications/Fake%20Company/attachments", "", "setup.exe", []byte("MZ\x90\x00\x03\x00\x00\x00"), http.StatusUnsupportedMediaType},
+		{"zip not named docx", "/applications/Fake%20Company/attachments", "", "files.zip", []byte("PK\x03\x04\x14\x00\x00\x00"), http.StatusUnsupportedMediaType},
+		{"docx", "/applications/Fake%20Company/attachments", "cover_letter", "letter.docx", []byte("PK\x03\x04\x14\x00\x00\x00"), http.StatusCreated},
+		{"unknown kind", "/applications/Fake%20Company/attachments", "portfolio", "cv.pdf", testPDF, http.StatusBadRequest},
+		{"missing application", "/applications/Nobody/attachments", "", "cv.pdf", testPDF, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := uploadAttachment(t, app, router, token, test.url, test.kind, test.filename, test.contents)
+			defer response.Body.Close()
+
+			if response.StatusCode != test.expected {
+				response_body, _ := io.ReadAll(response.Body)
+				t.Errorf("Expected status code %d, got %d: %s", test.expected, response.StatusCode, response_body)
+			}
+		})
+	}
+
+	// Only the two accepted uploads were stored, and the orphan from the missing application was cleaned up.
+	attachments, _ := app.DB.ListAttachments(t.Context(), "testuser", "Fake Company")
+	if len(attachments) != 2 {
+		t.Errorf("Expected 2 stored attachments, got %d", len(attachments))
+	}
+}
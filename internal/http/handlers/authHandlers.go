@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 
+	"go.uber.org/zap"
+
 	"github.com/medidew/ApplicationTracker/internal/auth"
 )
 
@@ -12,10 +14,19 @@ func (app *App) Register(response_writer http.ResponseWriter, request *http.Requ
 	password := request.FormValue("password")
 
 	argon2auth := &auth.Argon2Auth{}
-	argon2auth.SetDefaults()
+	err := argon2auth.SetDefaults()
+	if err != nil {
+		http.Error(response_writer, "failed to setup password hashing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	argon2auth.Argon2Memory = app.Config.Auth.Argon2Memory
+	argon2auth.Argon2Time = app.Config.Auth.Argon2Time
+	argon2auth.Argon2Threads = app.Config.Auth.Argon2Threads
+
 	hashed_password := argon2auth.HashPassword([]byte(password))
 
-	err := app.DB.CreateUser(
+	err = app.DB.CreateUser(
+		request.Context(),
 		request.FormValue("email"),
 		request.FormValue("username"),
 		argon2auth,
@@ -36,28 +47,30 @@ func (app *App) Register(response_writer http.ResponseWriter, request *http.Requ
 
 func (app *App) Login(response_writer http.ResponseWriter, request *http.Request) {
 	username := request.FormValue("username")
-	app.Logger.Info("user login request: " + username)
+	app.logger(request).Info("user login request", zap.String("login_username", username))
 	password := request.FormValue("password")
 
-	hashed_password, err := app.DB.GetUserHashedPassword(username)
+	hashed_password, err := app.DB.GetUserHashedPassword(request.Context(), username)
 	if err != nil {
 		http.Error(response_writer, "failed to get user hashed password: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	argon2auth, err := app.DB.GetUserArgon2Auth(username)
+	argon2auth, err := app.DB.GetUserArgon2Auth(request.Context(), username)
 	if err != nil {
 		http.Error(response_writer, "failed to get user argon2 auth: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	if !argon2auth.VerifyPassword([]byte(password), hashed_password) {
+		app.Metrics.Logins.WithLabelValues("failure").Inc()
 		http.Error(response_writer, "invalid username or password", http.StatusUnauthorized)
 		return
 	}
 
 	app.SessionManager.RenewToken(request.Context())
 	app.SessionManager.Put(request.Context(), "username", username)
+	app.Metrics.Logins.WithLabelValues("success").Inc()
 
 	_, err = response_writer.Write([]byte("Login successful"))
 	if err != nil {
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	maxBatchBytes      int64 = 1 << 20
+	maxBatchOperations int   = 100
+)
+
+type batchMode string
+
+const (
+	batchAllOrNothing batchMode = "all_or_nothing" // The default. Any failure rolls back the whole batch.
+	batchBestEffort   batchMode = "best_effort"    // Each operation is applied or rolled back on its own.
+)
+
+const (
+	batchUpdateStatus string = "update_status"
+	batchAddTag       string = "add_tag"
+	batchArchive      string = "archive"
+	batchDelete       string = "delete"
+)
+
+type batchOutcome string
+
+const (
+	batchApplied    batchOutcome = "applied"
+	batchFailed     batchOutcome = "failed"
+	batchRolledBack batchOutcome = "rolled_back" // Succeeded, but a later failure undid the all-or-nothing batch.
+	batchSkipped    batchOutcome = "skipped"     // Not attempted, as an earlier failure ended the all-or-nothing batch.
+)
+
+// Returned from the transaction to roll back an all-or-nothing batch.
+var errBatchAborted = errors.New("batch aborted")
+
+type batchOperation struct {
+	Op      string          `json:"op"`
+	Company string          `json:"company"`
+	Status  json.RawMessage `json:"status,omitempty"`  // For update_status, a name such as "Rejected" or its number.
+	TagID   int64           `json:"tag_id,omitempty"`  // For add_tag.
+	Version int64           `json:"version,omitempty"` // For update_status, archive and delete, checked like If-Match. 0 skips the check.
+
+	status store.ApplicationStatus // Parsed from Status by validate.
+}
+
+type batchResult struct {
+	Index   int          `json:"index"`
+	Op      string       `json:"op"`
+	Company string       `json:"company"`
+	Result  batchOutcome `json:"result"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type batchReport struct {
+	Mode    batchMode     `json:"mode"`
+	Applied int           `json:"applied"`
+	Failed  int           `json:"failed"`
+	Results []batchResult `json:"results"`
+}
+
+func (operation *batchOperation) validate() error {
+	if operation.Company == "" {
+		return errors.New("company is required")
+	}
+	if operation.Version < 0 {
+		return errors.New("version must not be negative")
+	}
+
+	switch operation.Op {
+	case batchUpdateStatus:
+		// Accepts a JSON string or number, so both "Rejected" and 2 work.
+		var status_name string
+		if json.Unmarshal(operation.Status, &status_name) != nil {
+			status_name = string(operation.Status)
+		}
+
+		status, err := store.ParseApplicationStatus(status_name)
+		if err != nil {
+			return err
+		}
+		operation.status = status
+	case batchAddTag:
+		if operation.TagID <= 0 {
+			return errors.New("tag_id is required")
+		}
+	case batchArchive, batchDelete:
+	default:
+		return fmt.Errorf("op %q is not one of %s, %s, %s or %s", operation.Op, batchUpdateStatus, batchAddTag, batchArchive, batchDelete)
+	}
+
+	return nil
+}
+
+func (operation *batchOperation) apply(ctx context.Context, tx store.Store, username string) error {
+	switch operation.Op {
+	case batchUpdateStatus:
+		return tx.UpdateApplicationStatus(ctx, username, operation.Company, operation.status, operation.Version)
+	case batchAddTag:
+		return tx.TagApplication(ctx, username, operation.Company, operation.TagID)
+	case batchArchive:
+		return tx.ArchiveApplication(ctx, username, operation.Company, operation.Version)
+	case batchDelete:
+		return tx.DeleteApplication(ctx, username, operation.Company, operation.Version)
+	}
+
+	return fmt.Errorf("unknown op %q", operation.Op)
+}
+
+// Applies up to 100 operations across the user's applications in one transaction, e.g.
+//
+//	{"mode": "best_effort", "operations": [{"op": "update_status", "company": "Acme", "status": "Rejected"}]}
+//
+// Ops are update_status, add_tag (with `tag_id`), archive and delete. By default the batch is all-or-nothing,
+// responding 422 if any operation fails. With `"mode": "best_effort"` each failure is rolled back alone and
+// the rest are kept. Either way the report lists every operation's result, in order.
+func (app *App) BatchApplications(response_writer http.ResponseWriter, request *http.Request) {
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	var batch struct {
+		Mode       batchMode         `json:"mode"`
+		Operations []*batchOperation `json:"operations"`
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(response_writer, request.Body, maxBatchBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&batch)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if batch.Mode == "" {
+		batch.Mode = batchAllOrNothing
+	}
+	if batch.Mode != batchAllOrNothing && batch.Mode != batchBestEffort {
+		http.Error(response_writer, fmt.Sprintf("mode %q is not one of %s or %s", batch.Mode, batchAllOrNothing, batchBestEffort), http.StatusBadRequest)
+		return
+	}
+	if len(batch.Operations) == 0 || len(batch.Operations) > maxBatchOperations {
+		http.Error(response_writer, fmt.Sprintf("a batch needs 1 to %d operations", maxBatchOperations), http.StatusBadRequest)
+		return
+	}
+
+	report := batchReport{Mode: batch.Mode, Results: make([]batchResult, len(batch.Operations))}
+	for i, operation := range batch.Operations {
+		if operation == nil {
+			http.Error(response_writer, fmt.Sprintf("operations[%d] is null", i), http.StatusBadRequest)
+			return
+		}
+
+		err = operation.validate()
+		if err != nil {
+			http.Error(response_writer, fmt.Sprintf("invalid operations[%d]: %v", i, err), http.StatusBadRequest)
+			return
+		}
+
+		report.Results[i] = batchResult{Index: i, Op: operation.Op, Company: operation.Company}
+	}
+
+	ctx := request.Context()
+	err = app.DB.WithTx(ctx, func(tx store.Store) error {
+		for i, operation := range batch.Operations {
+			var operation_err error
+			if batch.Mode == batchBestEffort {
+				// A savepoint each, so a failure only undoes its own operation.
+				operation_err = tx.WithTx(ctx, func(operation_tx store.Store) error {
+					return operation.apply(ctx, operation_tx, username)
+				})
+			} else {
+				operation_err = operation.apply(ctx, tx, username)
+			}
+
+			if operation_err == nil {
+				report.Results[i].Result = batchApplied
+				continue
+			}
+
+			report.Results[i].Result = batchFailed
+			report.Results[i].Error = operation_err.Error()
+
+			if batch.Mode == batchAllOrNothing {
+				for j := range report.Results[:i] {
+					report.Results[j].Result = batchRolledBack
+				}
+				for j := i + 1; j < len(report.Results); j++ {
+					report.Results[j].Result = batchSkipped
+				}
+				return errBatchAborted
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		http.Error(response_writer, "DB transaction failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, result := range report.Results {
+		switch result.Result {
+		case batchApplied:
+			report.Applied++
+		case batchFailed:
+			report.Failed++
+		}
+	}
+
+	status_code := http.StatusOK
+	if errors.Is(err, errBatchAborted) {
+		status_code = http.StatusUnprocessableEntity
+	}
+
+	writeJSON(response_writer, status_code, report)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func batchRequest(t *testing.T, app *App, router http.Handler, token string, body string) (int, batchReport) {
+	status, response_body := sendRequest(t, app, router, token, http.MethodPost, "/applications/batch", body)
+
+	var report batchReport
+	if status == http.StatusOK || status == http.StatusUnprocessableEntity {
+		err := json.Unmarshal([]byte(response_body), &report)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal report: %v: %s", err, response_body)
+		}
+	}
+
+	return status, report
+}
+
+func TestBatchApplicationsBestEffort(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	tag := &store.Tag{Name: "Hiring freeze"}
+	err = app.DB.CreateTag(context.Background(), "testuser", tag)
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	status, report := batchRequest(t, app, router, token, `{"mode": "best_effort", "operations": [
+		{"op": "update_status", "company": "Fake Company", "status": "Rejected"},
+		{"op": "add_tag", "company": "Fake Company", "tag_id": `+strconv.FormatInt(tag.ID, 10)+`},
+		{"op": "update_status", "company": "Missing Company", "status": 2},
+		{"op": "archive", "company": "Fake Company"},
+		{"op": "delete", "company": "Another Fake Company"}
+	]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	expected := []batchOutcome{batchApplied, batchApplied, batchFailed, batchApplied, batchApplied}
+	for i, result := range report.Results {
+		if result.Index != i || result.Result != expected[i] {
+			t.Errorf("Expected operation %d %s, got %+v", i, expected[i], result)
+		}
+	}
+	if report.Applied != 4 || report.Failed != 1 || report.Results[2].Error == "" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	application, err := app.DB.GetApplication(context.Background(), "testuser", "Fake Company")
+	if err != nil {
+		t.Fatalf("Failed to get application: %v", err)
+	}
+	if application.GetStatus() != store.Rejected || application.GetArchivedAt().IsZero() {
+		t.Errorf("Expected Fake Company rejected and archived, got %v archived at %v", application.GetStatus(), application.GetArchivedAt())
+	}
+
+	_, err = app.DB.GetApplication(context.Background(), "testuser", "Another Fake Company")
+	if err == nil {
+		t.Errorf("Expected Another Fake Company to be deleted")
+	}
+}
+
+func TestBatchApplicationsAllOrNothing(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, report := batchRequest(t, app, router, token, `{"operations": [
+		{"op": "update_status", "company": "Fake Company", "status": "Rejected"},
+		{"op": "delete", "company": "Another Fake Company", "version": 5},
+		{"op": "archive", "company": "Fake Company"}
+	]}`)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status code %d, got %d", http.StatusUnprocessableEntity, status)
+	}
+
+	expected := []batchOutcome{batchRolledBack, batchFailed, batchSkipped}
+	for i, result := range report.Results {
+		if result.Result != expected[i] {
+			t.Errorf("Expected operation %d %s, got %+v", i, expected[i], result)
+		}
+	}
+	if report.Mode != batchAllOrNothing || report.Applied != 0 || report.Failed != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	application, err := app.DB.GetApplication(context.Background(), "testuser", "Fake Company")
+	if err != nil {
+		t.Fatalf("Failed to get application: %v", err)
+	}
+	if application.GetStatus() != store.Active || application.GetVersion() != 1 {
+		t.Errorf("Expected the status update rolled back, got %v at version %d", application.GetStatus(), application.GetVersion())
+	}
+}
+
+func TestBatchApplicationsRejectsInvalidBatches(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no operations", `{"operations": []}`},
+		{"unknown mode", `{"mode": "sometimes", "operations": [{"op": "archive", "company": "Fake Company"}]}`},
+		{"unknown op", `{"operations": [{"op": "rename", "company": "Fake Company"}]}`},
+		{"missing company", `{"operations": [{"op": "archive"}]}`},
+		{"unknown status", `{"operations": [{"op": "update_status", "company": "Fake Company", "status": "Ghosted"}]}`},
+		{"missing tag", `{"operations": [{"op": "add_tag", "company": "Fake Company"}]}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, _ := batchRequest(t, app, router, token, test.body)
+			if status != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+			}
+		})
+	}
+}
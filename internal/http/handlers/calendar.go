@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RFC 5545 limits content lines to 75 octets, excluding the CRLF.
+const calendarLineOctets int = 75
+
+// A single VEVENT. Events without an End are given no duration.
+type calendarEvent struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+}
+
+// Writes an iCalendar (RFC 5545) stream. Call Close to write the footer and flush.
+type calendarWriter struct {
+	writer *bufio.Writer
+	stamp  string
+}
+
+func newCalendarWriter(writer io.Writer) (*calendarWriter, error) {
+	calendar := &calendarWriter{
+		writer: bufio.NewWriter(writer),
+		stamp:  formatCalendarTime(time.Now()),
+	}
+
+	for _, line := range []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//ApplicationTracker//Export//EN", "CALSCALE:GREGORIAN"} {
+		err := calendar.writeLine(line)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return calendar, nil
+}
+
+func (calendar *calendarWriter) WriteEvent(event calendarEvent) error {
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + escapeCalendarText(event.UID),
+		"DTSTAMP:" + calendar.stamp,
+		"DTSTART:" + formatCalendarTime(event.Start),
+	}
+	if !event.End.IsZero() {
+		lines = append(lines, "DTEND:"+formatCalendarTime(event.End))
+	}
+	lines = append(lines, "SUMMARY:"+escapeCalendarText(event.Summary))
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeCalendarText(event.Description))
+	}
+	if event.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeCalendarText(event.Location))
+	}
+	lines = append(lines, "END:VEVENT")
+
+	for _, line := range lines {
+		err := calendar.writeLine(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flushes buffered events to the underlying writer.
+func (calendar *calendarWriter) Flush() error {
+	return calendar.writer.Flush()
+}
+
+func (calendar *calendarWriter) Close() error {
+	err := calendar.writeLine("END:VCALENDAR")
+	if err != nil {
+		return err
+	}
+	return calendar.writer.Flush()
+}
+
+// Writes `line` folded into 75-octet chunks, never splitting a UTF-8 sequence.
+func (calendar *calendarWriter) writeLine(line string) error {
+	limit := calendarLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		_, err := calendar.writer.WriteString(line[:cut] + "\r\n ")
+		if err != nil {
+			return err
+		}
+
+		line = line[cut:]
+		limit = calendarLineOctets - 1 // Continuation lines start with the folding space.
+	}
+
+	_, err := calendar.writer.WriteString(line + "\r\n")
+	return err
+}
+
+func formatCalendarTime(value time.Time) string {
+	return value.UTC().Format("20060102T150405Z")
+}
+
+var calendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeCalendarText(value string) string {
+	return calendarTextEscaper.Replace(value)
+}
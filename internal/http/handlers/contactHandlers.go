@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func contactIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "contactID"), 10, 64)
+}
+
+// Decodes and validates a contact from the request body. The ID is ignored, it comes from the URL or the DB.
+func decodeContact(request *http.Request) (*store.Contact, error) {
+	contact := &store.Contact{}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.ID = 0
+	return contact, contact.Validate()
+}
+
+func (app *App) ListContacts(response_writer http.ResponseWriter, request *http.Request) {
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	contacts, err := app.DB.ListContacts(request.Context(), username)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, contacts)
+}
+
+func (app *App) GetContact(response_writer http.ResponseWriter, request *http.Request) {
+	contactID, err := contactIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	contact, err := app.DB.GetContact(request.Context(), username, contactID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, contact)
+}
+
+// Creates a contact and responds with it, including its new ID.
+func (app *App) CreateContact(response_writer http.ResponseWriter, request *http.Request) {
+	contact, err := decodeContact(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateContact(request.Context(), username, contact)
+	if err != nil {
+		http.Error(response_writer, "DB insert failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, contact)
+}
+
+// Replaces every field of a contact.
+func (app *App) UpdateContact(response_writer http.ResponseWriter, request *http.Request) {
+	contactID, err := contactIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contact, err := decodeContact(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	contact.ID = contactID
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateContact(request.Context(), username, contact)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, contact)
+}
+
+func (app *App) DeleteContact(response_writer http.ResponseWriter, request *http.Request) {
+	contactID, err := contactIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.DeleteContact(request.Context(), username, contactID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) ListApplicationContacts(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	contacts, err := app.DB.ListApplicationContacts(request.Context(), username, companyID)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, contacts)
+}
+
+// Links an existing contact to the application with `{"contact_id": 1}`.
+// Links don't change the application's version, so If-Match isn't checked.
+func (app *App) LinkApplicationContact(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+
+	var link struct {
+		ContactID int64 `json:"contact_id"`
+	}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&link)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.LinkContact(request.Context(), username, companyID, link.ContactID)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) UnlinkApplicationContact(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	contactID, err := contactIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid contact ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UnlinkContact(request.Context(), username, companyID, contactID)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Sends a request as testuser through `router`, returning the status code and body.
+func sendRequest(t *testing.T, app *App, router http.Handler, token string, method string, url string, body string) (int, string) {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	response_body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	return response.StatusCode, string(response_body)
+}
+
+func TestContactsCRUD(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/contacts", `{"name": "Jane Doe", "email": "jane@example.com", "company": "Fake Company", "role": "recruiter"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+
+	var created map[string]any
+	err = json.Unmarshal([]byte(body), &created)
+	if err != nil || created["id"].(float64) != 1 {
+		t.Fatalf("Expected the created contact with its ID, got %s", body)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodPut, "/contacts/1", `{"name": "Jane Doe", "role": "hiring_manager"}`)
+	if status != http.StatusOK || !strings.Contains(body, `"role":"hiring_manager"`) {
+		t.Fatalf("Unexpected update response %d: %s", status, body)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/contacts", "")
+	if status != http.StatusOK || !strings.Contains(body, `"hiring_manager"`) {
+		t.Fatalf("Unexpected list response %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/contacts/1", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/contacts/1", "")
+	if status != http.StatusNotFound {
+		t.Fatalf("Expected status code %d after delete, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestCreateContactInvalid(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	for _, body := range []string{`{"email": "jane@example.com"}`, `{"name": "Jane", "role": "landlord"}`, `{"name": "Jane", "age": 30}`} {
+		status, _ := sendRequest(t, app, router, token, http.MethodPost, "/contacts", body)
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", body, http.StatusBadRequest, status)
+		}
+	}
+}
+
+func TestApplicationContactLinks(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/contacts", `{"name": "Jane Doe", "role": "recruiter"}`)
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/contacts", `{"contact_id": 1}`)
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"contacts":[{"id":1,"name":"Jane Doe"`) {
+		t.Fatalf("Expected the contact in the application detail, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/contacts", `{"contact_id": 99}`)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d linking a missing contact, got %d", http.StatusNotFound, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company/contacts/1", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/contacts", "")
+	if status != http.StatusOK || body != "[]" {
+		t.Errorf("Expected no contacts after unlinking, got %d: %s", status, body)
+	}
+}
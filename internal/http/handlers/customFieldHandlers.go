@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func customFieldIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "fieldID"), 10, 64)
+}
+
+// Decodes and validates a custom field from the request body. The ID is ignored, it comes from the URL or the DB.
+func decodeCustomField(request *http.Request) (*store.CustomField, error) {
+	field := &store.CustomField{}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(field)
+	if err != nil {
+		return nil, err
+	}
+
+	field.ID = 0
+	return field, field.Validate()
+}
+
+func (app *App) ListCustomFields(response_writer http.ResponseWriter, request *http.Request) {
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	fields, err := app.DB.ListCustomFields(request.Context(), username)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, fields)
+}
+
+// Adds a field to the user's schema and responds with it, including its new ID.
+func (app *App) CreateCustomField(response_writer http.ResponseWriter, request *http.Request) {
+	field, err := decodeCustomField(request)
+	if err != nil {
+		http.Error(response_writer, "invalid custom field: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateCustomField(request.Context(), username, field)
+	if err != nil {
+		writeStoreError(response_writer, "DB insert failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, field)
+}
+
+// Renames a field or changes its options. Its type must stay the same.
+func (app *App) UpdateCustomField(response_writer http.ResponseWriter, request *http.Request) {
+	fieldID, err := customFieldIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid custom field ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	field, err := decodeCustomField(request)
+	if err != nil {
+		http.Error(response_writer, "invalid custom field: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	field.ID = fieldID
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateCustomField(request.Context(), username, field)
+	if errors.Is(err, store.ErrCustomFieldType) {
+		http.Error(response_writer, "invalid custom field: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, field)
+}
+
+// Removes a field from the schema, along with its value on every application.
+func (app *App) DeleteCustomField(response_writer http.ResponseWriter, request *http.Request) {
+	fieldID, err := customFieldIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid custom field ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.DeleteCustomField(request.Context(), username, fieldID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Replaces the application's custom field values with the JSON object in the body, keyed by field name.
+func (app *App) UpdateApplicationCustomFields(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+
+	expected_version, ok := app.ifMatchVersion(response_writer, request)
+	if !ok {
+		return
+	}
+
+	var values map[string]any
+
+	err := json.NewDecoder(request.Body).Decode(&values)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	values, ok = app.validateCustomFieldValues(response_writer, request, username, values)
+	if !ok {
+		return
+	}
+
+	err = app.DB.UpdateApplicationCustomFields(request.Context(), username, companyID, values, expected_version)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+// Checks custom field values against the user's schema, returning them normalised.
+// On failure it writes the error response and returns false.
+func (app *App) validateCustomFieldValues(response_writer http.ResponseWriter, request *http.Request, username string, values map[string]any) (map[string]any, bool) {
+	if len(values) == 0 {
+		return map[string]any{}, true
+	}
+
+	schema, err := app.DB.ListCustomFields(request.Context(), username)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	values, err = store.ValidateCustomFieldValues(schema, values)
+	if err != nil {
+		http.Error(response_writer, "invalid custom fields: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return values, true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCustomFields(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/custom-fields", `{"name": "Tech stack", "type": "enum", "options": ["Go", "Rust"]}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+	sendRequest(t, app, router, token, http.MethodPost, "/custom-fields", `{"name": "Team size", "type": "number"}`)
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/custom-fields", `{"name": "team SIZE", "type": "text"}`)
+	if status != http.StatusConflict {
+		t.Errorf("Expected status code %d for a duplicate name, got %d", http.StatusConflict, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodPost, "/applications", `{
+		"company": "Custom Company",
+		"role": "Software Engineer",
+		"custom_fields": {"Tech stack": "Go", "Team size": 8}
+	}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Custom%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"custom_fields":{"Team size":8,"Tech stack":"Go"}`) {
+		t.Errorf("Expected custom fields in the application, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPut, "/custom-fields/1", `{"name": "Stack", "type": "enum", "options": ["Go", "Zig"]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Custom%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"custom_fields":{"Stack":"Go","Team size":8}`) {
+		t.Errorf("Expected the value to follow the rename, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPut, "/custom-fields/2", `{"name": "Team size", "type": "text"}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for a type change, got %d", http.StatusBadRequest, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/custom-fields/2", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Custom%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"custom_fields":{"Stack":"Go"}`) {
+		t.Errorf("Expected deleting the field to remove its value, got %d: %s", status, body)
+	}
+}
+
+func TestUpdateApplicationCustomFields(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/custom-fields", `{"name": "Visa sponsorship", "type": "bool"}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/custom-fields", `{"name": "Closing date", "type": "date"}`)
+
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		expected int
+	}{
+		{"valid", "/applications/Fake%20Company/custom-fields", `{"Visa sponsorship": true, "Closing date": "2025-08-01"}`, http.StatusNoContent},
+		{"unknown field", "/applications/Fake%20Company/custom-fields", `{"Remote": true}`, http.StatusBadRequest},
+		{"wrong type", "/applications/Fake%20Company/custom-fields", `{"Visa sponsorship": "yes"}`, http.StatusBadRequest},
+		{"bad date", "/applications/Fake%20Company/custom-fields", `{"Closing date": "August"}`, http.StatusBadRequest},
+		{"missing application", "/applications/Nobody/custom-fields", `{"Visa sponsorship": false}`, http.StatusNotFound},
+		{"unknown field on create", "/applications", `{"company": "X", "role": "Software Engineer", "custom_fields": {"Remote": true}}`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := http.MethodPut
+			if test.url == "/applications" {
+				method = http.MethodPost
+			}
+
+			status, body := sendRequest(t, app, router, token, method, test.url, test.body)
+			if status != test.expected {
+				t.Errorf("Expected status code %d, got %d: %s", test.expected, status, body)
+			}
+		})
+	}
+
+	job_application, _ := app.DB.GetApplication(t.Context(), "testuser", "Fake Company")
+	if job_application.GetCustomFields()["Closing date"] != "2025-08-01" {
+		t.Errorf("Expected the valid update to be kept, got %v", job_application.GetCustomFields())
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Formats an application's version as a strong entity tag, e.g. `"3"`.
+func applicationETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Reads the application version the client expects from If-Match, for the store to check atomically.
+// Returns 0 when there's nothing to check: no header, or `*`. Responds 428 when the header is missing but
+// required by config, 412 for a tag that can never match, such as a weak one, and 400 for a list of tags.
+func (app *App) ifMatchVersion(response_writer http.ResponseWriter, request *http.Request) (int64, bool) {
+	if_match := strings.TrimSpace(request.Header.Get("If-Match"))
+
+	if if_match == "" {
+		if app.Config.Server.RequireIfMatch {
+			http.Error(response_writer, "If-Match is required, send the ETag from GET", http.StatusPreconditionRequired)
+			return 0, false
+		}
+		return 0, true
+	}
+	if if_match == "*" {
+		return 0, true
+	}
+	if strings.Contains(if_match, ",") {
+		http.Error(response_writer, "If-Match with more than one entity tag is not supported", http.StatusBadRequest)
+		return 0, false
+	}
+
+	// If-Match uses strong comparison, so weak or malformed tags fail like a stale one.
+	version, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(if_match, `"`), `"`), 10, 64)
+	if err != nil || version <= 0 || if_match != applicationETag(version) {
+		http.Error(response_writer, "If-Match does not match the current ETag", http.StatusPreconditionFailed)
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Like sendRequest, with an If-Match header unless `if_match` is empty, returning the ETag rather than the body.
+func sendConditionalRequest(t *testing.T, app *App, router http.Handler, token string, method string, url string, if_match string, body string) (int, string) {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if if_match != "" {
+		request.Header.Set("If-Match", if_match)
+	}
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	return response_recorder.Code, response_recorder.Header().Get("ETag")
+}
+
+func TestApplicationETag(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, etag := sendConditionalRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "", "")
+	if status != http.StatusOK || etag != `"1"` {
+		t.Fatalf("Expected ETag %q, got %d %q", `"1"`, status, etag)
+	}
+
+	// Two tabs load version 1, and the second tab's update must not overwrite the first's.
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company", `"1"`, `{"status": 1}`)
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company", `"1"`, `{"status": 2}`)
+	if status != http.StatusPreconditionFailed {
+		t.Fatalf("Expected status code %d for a stale ETag, got %d", http.StatusPreconditionFailed, status)
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"status":"Pending Response"`) || !strings.Contains(body, `"version":2`) {
+		t.Errorf("Expected the first update kept at version 2, got %d: %s", status, body)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		if_match string
+		body     string
+		expected int
+	}{
+		{"weak tag", http.MethodDelete, "/applications/Fake%20Company/notes/1", `W/"2"`, "", http.StatusPreconditionFailed},
+		{"unquoted tag", http.MethodDelete, "/applications/Fake%20Company/notes/1", `2`, "", http.StatusPreconditionFailed},
+		{"tag list", http.MethodDelete, "/applications/Fake%20Company/notes/1", `"2", "3"`, "", http.StatusBadRequest},
+		{"stale details", http.MethodPut, "/applications/Fake%20Company/details", `"1"`, `{}`, http.StatusPreconditionFailed},
+		{"stale custom fields", http.MethodPut, "/applications/Fake%20Company/custom-fields", `"1"`, `{}`, http.StatusPreconditionFailed},
+		{"stale follow-up", http.MethodPut, "/applications/Fake%20Company/follow-up", `"1"`, `{"follow_up_at": null}`, http.StatusPreconditionFailed},
+		{"stale delete", http.MethodDelete, "/applications/Fake%20Company", `"1"`, "", http.StatusPreconditionFailed},
+		{"missing application", http.MethodPut, "/applications/Nobody/details", `"1"`, `{}`, http.StatusNotFound},
+		{"current note removal", http.MethodDelete, "/applications/Fake%20Company/notes/1", `"2"`, "", http.StatusNoContent},
+		{"any version", http.MethodPut, "/applications/Fake%20Company/details", `*`, `{}`, http.StatusNoContent},
+		{"current delete", http.MethodDelete, "/applications/Fake%20Company", `"4"`, "", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		status, _ := sendConditionalRequest(t, app, router, token, test.method, test.url, test.if_match, test.body)
+		if status != test.expected {
+			t.Errorf("%s: expected status code %d, got %d", test.name, test.expected, status)
+		}
+	}
+}
+
+func TestIfMatchOnPostRoutes(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	// Each route bumps the version, so every step's current tag is one more than the last.
+	tests := []struct {
+		name     string
+		url      string
+		if_match string
+		body     string
+		expected int
+	}{
+		{"stale note", "/applications/Another%20Fake%20Company/notes", `"2"`, `{"note": "Note."}`, http.StatusPreconditionFailed},
+		{"current note", "/applications/Another%20Fake%20Company/notes", `"1"`, `{"note": "Note."}`, http.StatusCreated},
+		{"stale archive", "/applications/Another%20Fake%20Company/archive", `"1"`, "", http.StatusPreconditionFailed},
+		{"current archive", "/applications/Another%20Fake%20Company/archive", `"2"`, "", http.StatusNoContent},
+		{"stale unarchive", "/applications/Another%20Fake%20Company/unarchive", `"2"`, "", http.StatusPreconditionFailed},
+		{"current unarchive", "/applications/Another%20Fake%20Company/unarchive", `"3"`, "", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		status, _ := sendConditionalRequest(t, app, router, token, http.MethodPost, test.url, test.if_match, test.body)
+		if status != test.expected {
+			t.Errorf("%s: expected status code %d, got %d", test.name, test.expected, status)
+		}
+	}
+
+	status, _ := sendConditionalRequest(t, app, router, token, http.MethodDelete, "/applications/Another%20Fake%20Company", `"4"`, "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	// A trashed application's version is listed in the trash.
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/restore", `"4"`, "")
+	if status != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d restoring with a stale ETag, got %d", http.StatusPreconditionFailed, status)
+	}
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/restore", `"5"`, "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status code %d restoring with the current ETag, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestRequireIfMatch(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	app.Config.Server.RequireIfMatch = true
+
+	status, _ := sendConditionalRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company", "", `{"status": 1}`)
+	if status != http.StatusPreconditionRequired {
+		t.Errorf("Expected status code %d without If-Match, got %d", http.StatusPreconditionRequired, status)
+	}
+
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company", `"1"`, `{"status": 1}`)
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status code %d with If-Match, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestCreatedApplicationETagRoundTrip(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications", `{"company": "New Company", "role": "Software Engineer", "status": 0, "notes": []}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, status)
+	}
+
+	status, etag := sendConditionalRequest(t, app, router, token, http.MethodGet, "/applications/New%20Company", "", "")
+	if status != http.StatusOK || etag != `"1"` {
+		t.Fatalf("Expected ETag %q for a new application, got %d %q", `"1"`, status, etag)
+	}
+
+	status, _ = sendConditionalRequest(t, app, router, token, http.MethodPut, "/applications/New%20Company", etag, `{"status": 1}`)
+	if status != http.StatusNoContent {
+		t.Errorf("Expected the ETag from GET to be accepted, got %d", status)
+	}
+}
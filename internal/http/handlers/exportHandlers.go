@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// How many applications are written between flushes while streaming an export.
+const exportFlushEvery int = 100
+
+// Content type and file extension for each export format.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv"},
+	"json":   {"application/json", "json"},
+	"ndjson": {"application/x-ndjson", "ndjson"},
+	"ics":    {"text/calendar; charset=utf-8", "ics"},
+}
+
+// Columns of a CSV export, in order. Notes are joined by newlines into one cell, and unknown details are empty.
+var exportCSVHeader = []string{
+	"company", "role", "status", "notes", "created_at", "updated_at", "follow_up_at",
+	"salary_min", "salary_max", "salary_currency", "salary_period", "location", "remote_policy", "employment_type", "source",
+}
+
+// Writes exported applications one at a time, so large exports aren't held in memory.
+type exportEncoder interface {
+	Encode(job_application *store.JobApplication) error
+	Flush() error
+	Close() error
+}
+
+// Downloads the user's applications as `?format=csv`, `json` (the default), `ndjson` or `ics`.
+//
+// Accepts the same filters as the list endpoint. The ics format has an event per scheduled follow-up and interview.
+func (app *App) ExportApplications(response_writer http.ResponseWriter, request *http.Request) {
+	format := request.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	export_format, ok := exportFormats[format]
+	if !ok {
+		http.Error(response_writer, "unsupported format "+format+", expected csv, json, ndjson or ics", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseApplicationFilter(request)
+	if err != nil {
+		http.Error(response_writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	// Interviews are looked up per application while streaming, so load them before the response starts.
+	interviews := map[string][]*store.Interview{}
+	if format == "ics" {
+		all_interviews, err := app.DB.ListInterviews(request.Context(), username, store.InterviewFilter{})
+		if err != nil {
+			http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, interview := range all_interviews {
+			interviews[interview.Company] = append(interviews[interview.Company], interview)
+		}
+	}
+
+	response_writer.Header().Set("Content-Type", export_format.contentType)
+	response_writer.Header().Set("Content-Disposition", `attachment; filename="applications.`+export_format.extension+`"`)
+	response_writer.WriteHeader(http.StatusOK)
+
+	encoder, err := newExportEncoder(format, response_writer, username, interviews)
+	if err == nil {
+		flusher, _ := response_writer.(http.Flusher)
+		written := 0
+
+		err = app.DB.StreamApplications(request.Context(), username, filter, func(job_application *store.JobApplication) error {
+			err := encoder.Encode(job_application)
+			if err != nil {
+				return err
+			}
+
+			written++
+			if written%exportFlushEvery == 0 && flusher != nil {
+				err = encoder.Flush()
+				flusher.Flush()
+			}
+			return err
+		})
+		if err == nil {
+			err = encoder.Close()
+		}
+	}
+
+	// The status is already sent, so all we can do is stop the stream short and log why.
+	if err != nil {
+		app.logger(request).Error("export failed", zap.String("format", format), zap.Error(err))
+	}
+}
+
+func newExportEncoder(format string, writer io.Writer, username string, interviews map[string][]*store.Interview) (exportEncoder, error) {
+	switch format {
+	case "csv":
+		return newCSVExportEncoder(writer)
+	case "ndjson":
+		return &ndjsonExportEncoder{encoder: json.NewEncoder(writer)}, nil
+	case "ics":
+		calendar, err := newCalendarWriter(writer)
+		if err != nil {
+			return nil, err
+		}
+		return &calendarExportEncoder{calendar: calendar, username: username, interviews: interviews}, nil
+	default:
+		return &jsonExportEncoder{writer: writer}, nil
+	}
+}
+
+type csvExportEncoder struct {
+	writer *csv.Writer
+}
+
+func newCSVExportEncoder(writer io.Writer) (*csvExportEncoder, error) {
+	encoder := &csvExportEncoder{writer: csv.NewWriter(writer)}
+	return encoder, encoder.writer.Write(exportCSVHeader)
+}
+
+func (encoder *csvExportEncoder) Encode(job_application *store.JobApplication) error {
+	details := job_application.GetDetails()
+	salary := make([]string, 4)
+	if details.Salary != nil {
+		salary = []string{
+			strconv.FormatFloat(details.Salary.Min, 'f', -1, 64),
+			strconv.FormatFloat(details.Salary.Max, 'f', -1, 64),
+			details.Salary.Currency,
+			string(details.Salary.Period),
+		}
+	}
+
+	record := append([]string{
+		job_application.GetCompany(),
+		string(job_application.GetRole()),
+		job_application.GetStatus().String(),
+		strings.Join(job_application.GetNotes(), "\n"),
+		formatExportTime(job_application.GetCreatedAt()),
+		formatExportTime(job_application.GetUpdatedAt()),
+		formatExportTime(job_application.GetFollowUpAt()),
+	}, salary...)
+	record = append(record, details.Location, string(details.RemotePolicy), string(details.EmploymentType), string(details.Source))
+
+	for i, cell := range record {
+		record[i] = escapeCSVFormula(cell)
+	}
+
+	return encoder.writer.Write(record)
+}
+
+// Prefixes cells that spreadsheets would run as formulas with a quote, so they're shown as text.
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (encoder *csvExportEncoder) Flush() error {
+	encoder.writer.Flush()
+	return encoder.writer.Error()
+}
+
+func (encoder *csvExportEncoder) Close() error {
+	return encoder.Flush()
+}
+
+// Streams a JSON array, writing the brackets and separators around each marshalled application.
+type jsonExportEncoder struct {
+	writer  io.Writer
+	started bool
+}
+
+func (encoder *jsonExportEncoder) Encode(job_application *store.JobApplication) error {
+	separator := ","
+	if !encoder.started {
+		separator = "["
+		encoder.started = true
+	}
+
+	application_json, err := json.Marshal(job_application)
+	if err != nil {
+		return err
+	}
+
+	_, err = encoder.writer.Write(append([]byte(separator), application_json...))
+	return err
+}
+
+func (encoder *jsonExportEncoder) Flush() error {
+	return nil
+}
+
+func (encoder *jsonExportEncoder) Close() error {
+	closing := "]"
+	if !encoder.started {
+		closing = "[]"
+	}
+
+	_, err := io.WriteString(encoder.writer, closing)
+	return err
+}
+
+type ndjsonExportEncoder struct {
+	encoder *json.Encoder
+}
+
+func (encoder *ndjsonExportEncoder) Encode(job_application *store.JobApplication) error {
+	return encoder.encoder.Encode(job_application)
+}
+
+func (encoder *ndjsonExportEncoder) Flush() error {
+	return nil
+}
+
+func (encoder *ndjsonExportEncoder) Close() error {
+	return nil
+}
+
+// Writes an event for each scheduled follow-up and each interview that hasn't been cancelled.
+type calendarExportEncoder struct {
+	calendar   *calendarWriter
+	username   string
+	interviews map[string][]*store.Interview // By company.
+}
+
+func (encoder *calendarExportEncoder) Encode(job_application *store.JobApplication) error {
+	if !job_application.GetFollowUpAt().IsZero() {
+		err := encoder.calendar.WriteEvent(calendarEvent{
+			UID:         "follow-up-" + url.PathEscape(encoder.username) + "-" + url.PathEscape(job_application.GetCompany()) + "@applicationtracker",
+			Start:       job_application.GetFollowUpAt(),
+			Summary:     "Follow up with " + job_application.GetCompany(),
+			Description: string(job_application.GetRole()) + " application, " + job_application.GetStatus().String() + ".",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, interview := range encoder.interviews[job_application.GetCompany()] {
+		if interview.Outcome == store.InterviewCancelled {
+			continue
+		}
+
+		err := encoder.calendar.WriteEvent(interviewCalendarEvent(interview))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func interviewCalendarEvent(interview *store.Interview) calendarEvent {
+	description := []string{}
+	if len(interview.Interviewers) > 0 {
+		description = append(description, "Interviewers: "+strings.Join(interview.Interviewers, ", "))
+	}
+	if interview.VideoLink != "" {
+		description = append(description, "Join: "+interview.VideoLink)
+	}
+
+	location := interview.Location
+	if location == "" {
+		location = interview.VideoLink
+	}
+
+	return calendarEvent{
+		UID:         "interview-" + strconv.FormatInt(interview.ID, 10) + "@applicationtracker",
+		Start:       interview.ScheduledAt,
+		End:         interview.EndsAt(),
+		Summary:     strings.ReplaceAll(string(interview.Type), "_", " ") + " interview with " + interview.Company,
+		Description: strings.Join(description, "\n"),
+		Location:    location,
+	}
+}
+
+func (encoder *calendarExportEncoder) Flush() error {
+	return encoder.calendar.Flush()
+}
+
+func (encoder *calendarExportEncoder) Close() error {
+	return encoder.calendar.Close()
+}
+
+// Formats times as RFC 3339 in UTC, or empty when unset.
+func formatExportTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Requests an export and returns the response with its body read.
+func exportRequest(t *testing.T, url string, setup func(app *App)) (*http.Response, string) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	if setup != nil {
+		setup(app)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	return response, string(body)
+}
+
+func TestExportApplicationsJSON(t *testing.T) {
+	response, body := exportRequest(t, "/applications/export", nil)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if !strings.Contains(response.Header.Get("Content-Disposition"), `filename="applications.json"`) {
+		t.Errorf("Unexpected Content-Disposition %q", response.Header.Get("Content-Disposition"))
+	}
+
+	var applications []map[string]any
+	err := json.Unmarshal([]byte(body), &applications)
+	if err != nil {
+		t.Fatalf("Export is not a JSON array: %v\n%s", err, body)
+	}
+	if len(applications) != 2 {
+		t.Fatalf("Expected 2 applications, got %d", len(applications))
+	}
+	if len(applications[0]["notes"].([]any)) != 2 {
+		t.Errorf("Expected notes to be exported, got %v", applications[0]["notes"])
+	}
+}
+
+func TestExportApplicationsJSONEmpty(t *testing.T) {
+	_, body := exportRequest(t, "/applications/export?format=json&company=nobody", nil)
+
+	if body != "[]" {
+		t.Errorf("Expected an empty array, got %q", body)
+	}
+}
+
+func TestExportApplicationsNDJSON(t *testing.T) {
+	response, body := exportRequest(t, "/applications/export?format=ndjson&status=pending%20response", nil)
+
+	if response.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Unexpected Content-Type %q", response.Header.Get("Content-Type"))
+	}
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line for the status filter, got %d:\n%s", len(lines), body)
+	}
+	if !strings.Contains(lines[0], `"company":"Another Fake Company"`) {
+		t.Errorf("Unexpected application %s", lines[0])
+	}
+}
+
+func TestExportApplicationsCSV(t *testing.T) {
+	_, body := exportRequest(t, "/applications/export?format=csv", nil)
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d records", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(exportCSVHeader, ",") {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	if records[1][3] != "Note one.\nNote two." {
+		t.Errorf("Expected notes joined by newlines, got %q", records[1][3])
+	}
+}
+
+func TestExportApplicationsCSVEscapesFormulas(t *testing.T) {
+	_, body := exportRequest(t, "/applications/export?format=csv", func(app *App) {
+		job_application, err := store.NewJobApplication("+Formula Company", store.SoftwareEngineer, store.Active, []string{"=HYPERLINK(\"http://example.com\")"}, store.ApplicationDetails{
+			Salary:   &store.SalaryRange{Min: 50000, Max: 60000, Currency: "GBP", Period: store.PerYear},
+			Location: "@Leeds",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create NewJobApplication: %v", err)
+		}
+		err = app.DB.CreateApplication(t.Context(), "testuser", job_application)
+		if err != nil {
+			t.Fatalf("Failed to create application: %v", err)
+		}
+	})
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+
+	var row map[string]string
+	for _, record := range records[1:] {
+		if record[0] == "'+Formula Company" {
+			row = map[string]string{}
+			for i, column := range exportCSVHeader {
+				row[column] = record[i]
+			}
+		}
+	}
+	if row == nil {
+		t.Fatalf("Expected a quoted row for +Formula Company in %v", records)
+	}
+
+	if row["notes"] != "'=HYPERLINK(\"http://example.com\")" || row["location"] != "'@Leeds" {
+		t.Errorf("Expected formula cells to be quoted, got notes %q and location %q", row["notes"], row["location"])
+	}
+	if row["salary_min"] != "50000" || row["salary_max"] != "60000" || row["salary_currency"] != "GBP" || row["salary_period"] != "year" {
+		t.Errorf("Expected the salary columns, got %v", row)
+	}
+}
+
+func TestExportApplicationsICS(t *testing.T) {
+	follow_up := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	response, body := exportRequest(t, "/applications/export?format=ics", func(app *App) {
+		err := app.DB.SetApplicationFollowUp(t.Context(), "testuser", "Fake Company", follow_up, 0)
+		if err != nil {
+			t.Fatalf("Failed to set follow-up: %v", err)
+		}
+	})
+
+	if response.Header.Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Errorf("Unexpected Content-Type %q", response.Header.Get("Content-Type"))
+	}
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(body, "END:VCALENDAR\r\n") {
+		t.Fatalf("Not a CRLF-delimited calendar:\n%s", body)
+	}
+	if strings.Count(body, "BEGIN:VEVENT") != 1 {
+		t.Fatalf("Expected 1 event for the one follow-up, got:\n%s", body)
+	}
+	if !strings.Contains(body, "DTSTART:20250314T093000Z\r\n") {
+		t.Errorf("Missing follow-up start time:\n%s", body)
+	}
+	if !strings.Contains(body, "SUMMARY:Follow up with Fake Company\r\n") {
+		t.Errorf("Missing summary:\n%s", body)
+	}
+}
+
+func TestExportApplicationsInvalid(t *testing.T) {
+	for _, url := range []string{
+		"/applications/export?format=xml",
+		"/applications/export?status=Interviewing",
+		"/applications/export?created_after=yesterday",
+	} {
+		response, _ := exportRequest(t, url, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", url, http.StatusBadRequest, response.StatusCode)
+		}
+	}
+}
+
+func TestCalendarWriterFoldsAndEscapes(t *testing.T) {
+	var output strings.Builder
+
+	calendar, err := newCalendarWriter(&output)
+	if err != nil {
+		t.Fatalf("Failed to start calendar: %v", err)
+	}
+	err = calendar.WriteEvent(calendarEvent{
+		UID:         "test@example.com",
+		Start:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Summary:     "Call; then email, then wait",
+		Description: strings.Repeat("é", 100) + "\nsecond line",
+	})
+	if err != nil {
+		t.Fatalf("Failed to write event: %v", err)
+	}
+	err = calendar.Close()
+	if err != nil {
+		t.Fatalf("Failed to close calendar: %v", err)
+	}
+
+	if !strings.Contains(output.String(), `SUMMARY:Call\; then email\, then wait`) {
+		t.Errorf("Summary not escaped:\n%s", output.String())
+	}
+
+	for _, line := range strings.Split(output.String(), "\r\n") {
+		if len(line) > calendarLineOctets {
+			t.Errorf("Line of %d octets exceeds the limit: %q", len(line), line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(output.String(), "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("é", 100)+`\nsecond line`+"\r\n") {
+		t.Errorf("Description doesn't round-trip once unfolded:\n%s", unfolded)
+	}
+}
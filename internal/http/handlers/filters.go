@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Reads the list filters shared by the list and export endpoints from the query string.
+//
+// `status` and `role` may be repeated to match any of them. Statuses are names or numbers.
+// `company` matches a case-insensitive substring. `created_after` and `created_before` are RFC 3339 times or dates.
+// `remote_policy`, `employment_type` and `source` may be repeated like `status`, and `location` matches a substring.
+// `salary_currency` matches applications with a salary in that currency. `min_salary` is a yearly amount in
+// `salary_currency`, which it requires, and matches salaries reaching it once annualised.
+// `tag` may be repeated to match applications with any of the named tags, or all of them with `tag_match=all`.
+// Archived applications are left out unless `archived=true` (only them) or `archived=all` is given.
+func parseApplicationFilter(request *http.Request) (store.ApplicationFilter, error) {
+	query := request.URL.Query()
+	filter := store.ApplicationFilter{
+		Company:        strings.TrimSpace(query.Get("company")),
+		Location:       strings.TrimSpace(query.Get("location")),
+		SalaryCurrency: strings.TrimSpace(query.Get("salary_currency")),
+	}
+
+	for _, value := range query["status"] {
+		status, err := store.ParseApplicationStatus(value)
+		if err != nil {
+			return filter, errors.New("invalid status filter " + value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, value := range query["role"] {
+		filter.Roles = append(filter.Roles, store.JobRole(value))
+	}
+
+	for _, value := range query["remote_policy"] {
+		filter.RemotePolicies = append(filter.RemotePolicies, store.RemotePolicy(value))
+	}
+	for _, value := range query["employment_type"] {
+		filter.EmploymentTypes = append(filter.EmploymentTypes, store.EmploymentType(value))
+	}
+	for _, value := range query["source"] {
+		filter.Sources = append(filter.Sources, store.ApplicationSource(value))
+	}
+
+	filter.Tags = query["tag"]
+	switch query.Get("tag_match") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return filter, errors.New("invalid tag_match " + query.Get("tag_match") + ", expected any or all")
+	}
+
+	switch query.Get("archived") {
+	case "", "false":
+		filter.Archived = store.NotArchived
+	case "true":
+		filter.Archived = store.OnlyArchived
+	case "all":
+		filter.Archived = store.AnyArchived
+	default:
+		return filter, errors.New("invalid archived " + query.Get("archived") + ", expected true, false or all")
+	}
+
+	if value := query.Get("min_salary"); value != "" {
+		min_salary, err := strconv.ParseFloat(value, 64)
+		if err != nil || min_salary < 0 {
+			return filter, errors.New("invalid min_salary " + value)
+		}
+		if filter.SalaryCurrency == "" {
+			return filter, errors.New("min_salary requires salary_currency")
+		}
+		filter.MinSalary = min_salary
+	}
+
+	var err error
+	filter.CreatedAfter, err = parseFilterTime(query.Get("created_after"))
+	if err != nil {
+		return filter, errors.New("invalid created_after: " + err.Error())
+	}
+	filter.CreatedBefore, err = parseFilterTime(query.Get("created_before"))
+	if err != nil {
+		return filter, errors.New("invalid created_before: " + err.Error())
+	}
+
+	return filter, nil
+}
+
+// Parses an RFC 3339 time or a plain date (midnight UTC). Empty is the zero time.
+func parseFilterTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
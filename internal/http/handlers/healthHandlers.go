@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Set at link time, e.g. `-ldflags "-X .../handlers.buildTime=2025-01-01T00:00:00Z"`, as Go doesn't record it.
+var buildTime string
+
+// How long /readyz waits on each dependency before reporting it as down.
+const readinessCheckTimeout = 2 * time.Second
+
+// Reports that the process is up and serving requests. Deliberately checks nothing else.
+func (app *App) Healthz(response_writer http.ResponseWriter, request *http.Request) {
+	writeJSON(response_writer, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Reports whether the app can serve traffic: the database is reachable, migrations are current and the session store responds.
+func (app *App) Readyz(response_writer http.ResponseWriter, request *http.Request) {
+	ctx, cancel := context.WithTimeout(request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	checks := map[string]string{}
+	ready := true
+
+	// The probe is unauthenticated, so errors are only logged and the response just names the failure.
+	logger := app.logger(request)
+
+	err := app.DB.Ping(ctx)
+	if err != nil {
+		logger.Warn("Readiness check failed", zap.String("check", "database"), zap.Error(err))
+		checks["database"] = "unreachable"
+		ready = false
+	} else {
+		checks["database"] = "ok"
+
+		pending, err := app.DB.PendingMigrations(ctx)
+		if err != nil {
+			logger.Warn("Readiness check failed", zap.String("check", "migrations"), zap.Error(err))
+			checks["migrations"] = "failed to check"
+			ready = false
+		} else if len(pending) > 0 {
+			logger.Warn("Readiness check failed", zap.String("check", "migrations"), zap.Strings("pending", pending))
+			checks["migrations"] = "pending"
+			ready = false
+		} else {
+			checks["migrations"] = "ok"
+		}
+	}
+
+	// Any token works, we only care that the store answers.
+	_, _, err = app.SessionManager.Store.Find("readyz-probe")
+	if err != nil {
+		logger.Warn("Readiness check failed", zap.String("check", "sessions"), zap.Error(err))
+		checks["sessions"] = "unreachable"
+		ready = false
+	} else {
+		checks["sessions"] = "ok"
+	}
+
+	status := "ok"
+	status_code := http.StatusOK
+	if !ready {
+		status = "unavailable"
+		status_code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(response_writer, status_code, struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}{status, checks})
+}
+
+type buildInfo struct {
+	Commit     string `json:"commit"`
+	CommitTime string `json:"commit_time"`
+	Modified   bool   `json:"modified"` // Built from a dirty working tree.
+	BuildTime  string `json:"build_time"`
+	GoVersion  string `json:"go_version"`
+}
+
+// Reports the commit, build time and Go version the binary was built with.
+func (app *App) Version(response_writer http.ResponseWriter, request *http.Request) {
+	writeJSON(response_writer, http.StatusOK, readBuildInfo())
+}
+
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Commit:     "unknown",
+		CommitTime: "unknown",
+		BuildTime:  "unknown",
+		GoVersion:  runtime.Version(),
+	}
+
+	if buildTime != "" {
+		info.BuildTime = buildTime
+	}
+
+	build_info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range build_info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
+
+func writeJSON(response_writer http.ResponseWriter, status_code int, value any) {
+	response, err := json.Marshal(value)
+	if err != nil {
+		http.Error(response_writer, "failed to marshal: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response_writer.Header().Set("Content-Type", "application/json")
+	response_writer.WriteHeader(status_code)
+	response_writer.Write(response)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func TestHealthz(t *testing.T) {
+	app := setupTestApp()
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	app := setupTestApp()
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	var readiness struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	err := json.NewDecoder(response.Body).Decode(&readiness)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	for _, check := range []string{"database", "migrations", "sessions"} {
+		if readiness.Checks[check] != "ok" {
+			t.Errorf("Expected check %q to be ok, got %q", check, readiness.Checks[check])
+		}
+	}
+}
+
+// A FakeStore whose database checks fail with an error that mustn't reach the client.
+type unreachableStore struct {
+	*store.FakeStore
+}
+
+func (db unreachableStore) Ping(ctx context.Context) error {
+	return errors.New("dial tcp 10.0.0.5:5432: password authentication failed for user \"tracker\"")
+}
+
+func TestReadyzHidesErrorDetails(t *testing.T) {
+	app := setupTestApp()
+	app.DB = unreachableStore{app.DB.(*store.FakeStore)}
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	if response_recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status code %d, got %d", http.StatusServiceUnavailable, response_recorder.Code)
+	}
+
+	body := response_recorder.Body.String()
+	if strings.Contains(body, "10.0.0.5") || strings.Contains(body, "tracker") {
+		t.Errorf("Expected the database error to stay out of the response, got %s", body)
+	}
+	if !strings.Contains(body, `"database":"unreachable"`) {
+		t.Errorf("Expected the database check to be reported as unreachable, got %s", body)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	app := setupTestApp()
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/version", nil)
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	var info buildInfo
+	err := json.NewDecoder(response.Body).Decode(&info)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	} else if info.GoVersion == "" {
+		t.Fatalf("Expected go_version to be set")
+	}
+}
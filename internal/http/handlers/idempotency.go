@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	IdempotencyKeyHeader      string = "Idempotency-Key"
+	IdempotentReplayedHeader  string = "Idempotent-Replayed" // Set to "true" on responses replayed from an earlier request.
+	maxIdempotencyKeyLength   int    = 255
+	maxIdempotentRequestBytes int64  = maxImportBytes // The largest body any idempotent route accepts.
+
+	// How long storing or releasing a key may take. These run after the handler, when the client may be gone.
+	idempotencyStoreTimeout time.Duration = 5 * time.Second
+)
+
+// Makes POST routes safe to retry. The first response to a request with an Idempotency-Key header is
+// stored per user and key for the configured TTL, and replayed for retries with the same key. A retry
+// with a different method, URL, Content-Type or body is rejected with 422, and one that arrives while the
+// first is still being handled gets 409. Responses with a 5xx status aren't stored, so they can be retried.
+// Requests without the header, or without a session, are passed through untouched.
+func (app *App) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+		key := request.Header.Get(IdempotencyKeyHeader)
+		username := app.SessionManager.GetString(request.Context(), "username")
+		if key == "" || username == "" {
+			next.ServeHTTP(response_writer, request)
+			return
+		}
+
+		if !validIdempotencyKey(key) {
+			http.Error(response_writer, "Idempotency-Key must be 1 to 255 printable ASCII characters", http.StatusBadRequest)
+			return
+		}
+
+		request_body, err := io.ReadAll(http.MaxBytesReader(response_writer, request.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			var max_bytes_err *http.MaxBytesError
+			if errors.As(err, &max_bytes_err) {
+				http.Error(response_writer, "request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(response_writer, "failed to read request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Body = io.NopCloser(bytes.NewReader(request_body))
+
+		stored, err := app.DB.BeginIdempotentRequest(request.Context(), username, key, idempotentRequestHash(request, request_body), time.Now().Add(app.Config.Idempotency.TTL))
+		if errors.Is(err, store.ErrIdempotencyMismatch) {
+			http.Error(response_writer, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		if errors.Is(err, store.ErrIdempotencyInProgress) {
+			http.Error(response_writer, "a request with this Idempotency-Key is still in progress, retry later", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if stored != nil {
+			if stored.ContentType != "" {
+				response_writer.Header().Set("Content-Type", stored.ContentType)
+			}
+			response_writer.Header().Set(IdempotentReplayedHeader, "true")
+			response_writer.WriteHeader(stored.StatusCode)
+			_, err = response_writer.Write(stored.Body)
+			if err != nil {
+				app.logger(request).Warn("Failed to write replayed response", zap.Error(err))
+			}
+			return
+		}
+
+		var response_body bytes.Buffer
+		wrapped_writer := chi_middleware.NewWrapResponseWriter(response_writer, request.ProtoMajor)
+		wrapped_writer.Tee(&response_body)
+
+		completed := false
+		defer func() {
+			// Frees the key if the handler panicked, so the retry isn't stuck on 409 until the key expires.
+			if !completed {
+				app.releaseIdempotencyKey(request, username, key)
+			}
+		}()
+
+		next.ServeHTTP(wrapped_writer, request)
+		completed = true
+
+		status_code := wrapped_writer.Status()
+		if status_code == 0 {
+			status_code = http.StatusOK
+		}
+		if status_code >= http.StatusInternalServerError {
+			app.releaseIdempotencyKey(request, username, key)
+			return
+		}
+
+		// The client disconnecting is what prompts a retry, so the response must be stored even then.
+		ctx, cancel := idempotencyStoreContext(request)
+		defer cancel()
+
+		err = app.DB.CompleteIdempotentRequest(ctx, username, key, &store.IdempotentResponse{
+			StatusCode:  status_code,
+			ContentType: wrapped_writer.Header().Get("Content-Type"),
+			Body:        response_body.Bytes(),
+		})
+		if err != nil {
+			app.logger(request).Error("Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+		}
+	})
+}
+
+func (app *App) releaseIdempotencyKey(request *http.Request, username string, key string) {
+	ctx, cancel := idempotencyStoreContext(request)
+	defer cancel()
+
+	err := app.DB.ReleaseIdempotentRequest(ctx, username, key)
+	if err != nil {
+		app.logger(request).Error("Failed to release idempotency key", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Keeps the request's values but not its cancellation, with a timeout of its own.
+func idempotencyStoreContext(request *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(request.Context()), idempotencyStoreTimeout)
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Hashes everything that decides what a request does, so a reused key can be told apart from a retry.
+func idempotentRequestHash(request *http.Request, body []byte) []byte {
+	hash := sha256.New()
+	for _, part := range []string{request.Method, request.URL.RequestURI(), request.Header.Get("Content-Type")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+
+	return hash.Sum(nil)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Like sendRequest, with an Idempotency-Key header unless `key` is empty, returning the whole response.
+func sendIdempotentRequest(t *testing.T, app *App, router http.Handler, token string, method string, url string, key string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		request.Header.Set(IdempotencyKeyHeader, key)
+	}
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	return response_recorder
+}
+
+func TestIdempotentAddApplicationNote(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	// The import script's connection drops after the first request, and it retries with the same key.
+	for attempt := 0; attempt < 2; attempt++ {
+		response := sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", "note-1", `{"note": "Note three."}`)
+		if response.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d on attempt %d, got %d", http.StatusCreated, attempt+1, response.Code)
+		}
+
+		replayed := response.Header().Get(IdempotentReplayedHeader) == "true"
+		if replayed != (attempt == 1) {
+			t.Errorf("Expected only the retry to be replayed, attempt %d replayed: %v", attempt+1, replayed)
+		}
+	}
+
+	notes, err := app.DB.ListApplicationNotes(context.Background(), "testuser", "Fake Company")
+	if err != nil {
+		t.Fatalf("Failed to list notes: %v", err)
+	}
+	if len(notes) != 3 {
+		t.Errorf("Expected the note to be added once, got notes: %v", notes)
+	}
+
+	response := sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", "note-1", `{"note": "Note four."}`)
+	if response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d for a reused key with a different body, got %d", http.StatusUnprocessableEntity, response.Code)
+	}
+
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/notes", "note-1", `{"note": "Note three."}`)
+	if response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d for a reused key with a different URL, got %d", http.StatusUnprocessableEntity, response.Code)
+	}
+
+	// Keys are per user, so someone else's "note-1" is a new request.
+	other_token, err := setupSessionContext(app, "otheruser")
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	response = sendIdempotentRequest(t, app, router, other_token, http.MethodPost, "/applications", "note-1", `{"company": "Fake Company", "role": "Software Engineer", "status": 0, "notes": []}`)
+	if response.Code != http.StatusCreated || response.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("Expected a fresh %d for another user's key, got %d", http.StatusCreated, response.Code)
+	}
+}
+
+func TestIdempotentImportReplaysReport(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	body := `[{"company": "New Company", "role": "Software Engineer", "status": "Offer"}]`
+
+	first := sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/import", "import-1", body)
+	retry := sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/import", "import-1", body)
+
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the retry to replay %d %s, got %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("Expected the replayed Content-Type %q, got %q", first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	}
+
+	applications, err := app.DB.ListApplications(context.Background(), "testuser", store.ApplicationFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list applications: %v", err)
+	}
+	if len(applications) != 3 {
+		t.Errorf("Expected the import to run once, got %d applications", len(applications))
+	}
+}
+
+func TestIdempotencyKeyFailures(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	response := sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", "has space", `{"note": "Note three."}`)
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid key, got %d", http.StatusBadRequest, response.Code)
+	}
+
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", strings.Repeat("k", maxIdempotencyKeyLength+1), `{"note": "Note three."}`)
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an overlong key, got %d", http.StatusBadRequest, response.Code)
+	}
+
+	// A client error is the request's answer, so it's replayed like any other.
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Missing%20Company/notes", "missing-1", `{"note": "Note three."}`)
+	if response.Code != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, response.Code)
+	}
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Missing%20Company/notes", "missing-1", `{"note": "Note three."}`)
+	if response.Code != http.StatusNotFound || response.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the retry to replay %d, got %d", http.StatusNotFound, response.Code)
+	}
+
+	// Server errors aren't stored, so a retry runs again instead of replaying the error.
+	app.DB = failingNoteStore{app.DB.(*store.FakeStore)}
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", "failing-1", `{"note": "Note three."}`)
+	if response.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.Code)
+	}
+	response = sendIdempotentRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/notes", "failing-1", `{"note": "Note three."}`)
+	if response.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("Expected a failed request not to be replayed")
+	}
+}
+
+// A FakeStore that can't add notes, as if the DB were down.
+type failingNoteStore struct {
+	*store.FakeStore
+}
+
+func (db failingNoteStore) AddApplicationNote(ctx context.Context, username string, companyID string, note string, expectedVersion int64) error {
+	return errors.New("connection refused")
+}
+
+// A FakeStore whose idempotency writes fail on a cancelled context, as Postgres queries do.
+type contextCheckingStore struct {
+	*store.FakeStore
+}
+
+func (db contextCheckingStore) CompleteIdempotentRequest(ctx context.Context, username string, key string, response *store.IdempotentResponse) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.FakeStore.CompleteIdempotentRequest(ctx, username, key, response)
+}
+
+func (db contextCheckingStore) ReleaseIdempotentRequest(ctx context.Context, username string, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.FakeStore.ReleaseIdempotentRequest(ctx, username, key)
+}
+
+func TestIdempotentResponseStoredAfterClientDisconnects(t *testing.T) {
+	app, _, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	app.DB = contextCheckingStore{app.DB.(*store.FakeStore)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The client hangs up while the handler is still running.
+	handler := app.SessionManager.LoadAndSave(app.Idempotent(http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+		cancel()
+		response_writer.WriteHeader(http.StatusCreated)
+	})))
+
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/applications/Fake%20Company/notes", strings.NewReader(`{"note": "Note three."}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(IdempotencyKeyHeader, "disconnect-1")
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	retry := sendIdempotentRequest(t, app, SetupRouter(app), token, http.MethodPost, "/applications/Fake%20Company/notes", "disconnect-1", `{"note": "Note three."}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the retry to replay %d, got %d", http.StatusCreated, retry.Code)
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	maxImportBytes int64 = 5 << 20
+	maxImportRows  int   = 5000
+)
+
+// Fields an import can populate, and the CSV header each maps from by default.
+var importFields = []string{"company", "role", "status", "notes"}
+
+type importRowResult struct {
+	Row     int                 `json:"row"` // 1-based, not counting the CSV header.
+	Company string              `json:"company"`
+	Result  store.ImportOutcome `json:"result"`
+	Reason  string              `json:"reason,omitempty"`
+}
+
+type importReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []importRowResult `json:"rows"`
+}
+
+// A row as read from the upload, before validation.
+type importRow struct {
+	company string
+	role    string
+	status  string
+	notes   []string
+}
+
+// Imports many applications at once from a CSV file or a JSON array.
+//
+// CSV columns are matched by header: `company`, `role`, `status` and `notes` by default, or the header
+// given in the query param of the same name, e.g. `?company=Employer&role=Position`.
+// `?dry_run=true` validates and reports without saving anything.
+func (app *App) ImportApplications(response_writer http.ResponseWriter, request *http.Request) {
+	dry_run, err := strconv.ParseBool(request.URL.Query().Get("dry_run"))
+	if err != nil && request.URL.Query().Get("dry_run") != "" {
+		http.Error(response_writer, "invalid dry_run: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	media_type, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(response_writer, "missing or invalid Content-Type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body := http.MaxBytesReader(response_writer, request.Body, maxImportBytes)
+
+	var rows []importRow
+	switch media_type {
+	case "text/csv":
+		rows, err = parseImportCSV(body, importColumnMapping(request))
+	case "application/json":
+		rows, err = parseImportJSON(body)
+	default:
+		http.Error(response_writer, "unsupported Content-Type "+media_type+", expected text/csv or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(response_writer, "failed to parse import: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(rows) > maxImportRows {
+		http.Error(response_writer, fmt.Sprintf("too many rows, the limit is %d", maxImportRows), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	report := importReport{
+		DryRun: dry_run,
+		Rows:   make([]importRowResult, len(rows)),
+	}
+
+	valid_applications := []*store.JobApplication{}
+	valid_rows := []int{}
+
+	for i, row := range rows {
+		report.Rows[i] = importRowResult{Row: i + 1, Company: row.company}
+
+		job_application, err := row.toJobApplication()
+		if err != nil {
+			report.Rows[i].Result = store.ImportFailed
+			report.Rows[i].Reason = err.Error()
+			report.Failed++
+			continue
+		}
+
+		valid_applications = append(valid_applications, job_application)
+		valid_rows = append(valid_rows, i)
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	outcomes, err := app.DB.ImportApplications(request.Context(), username, valid_applications, dry_run)
+	if err != nil {
+		http.Error(response_writer, "DB import failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i, outcome := range outcomes {
+		row := &report.Rows[valid_rows[i]]
+		row.Result = outcome
+
+		switch outcome {
+		case store.ImportCreated:
+			report.Created++
+		case store.ImportSkipped:
+			row.Reason = "an application for this company already exists"
+			report.Skipped++
+		}
+	}
+
+	writeJSON(response_writer, http.StatusOK, report)
+}
+
+// Maps each import field to the CSV header it's read from, defaulting to the field name.
+func importColumnMapping(request *http.Request) map[string]string {
+	mapping := map[string]string{}
+	for _, field := range importFields {
+		header := request.URL.Query().Get(field)
+		if header == "" {
+			header = field
+		}
+		mapping[field] = header
+	}
+	return mapping
+}
+
+func parseImportCSV(body io.Reader, mapping map[string]string) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1 // Spreadsheet exports often have ragged rows.
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(errors.New("could not read CSV header"), err)
+	}
+
+	columns := map[string]int{}
+	for field, column_name := range mapping {
+		for i, header_name := range header {
+			if strings.EqualFold(strings.TrimSpace(header_name), column_name) {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := columns["company"]; !ok {
+		return nil, fmt.Errorf("CSV has no %q column for company", mapping["company"])
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := importRow{
+			company: cell(record, "company"),
+			role:    cell(record, "role"),
+			status:  cell(record, "status"),
+			notes:   []string{},
+		}
+		if note := cell(record, "notes"); note != "" {
+			row.notes = append(row.notes, note)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseImportJSON(body io.Reader) ([]importRow, error) {
+	var raw_rows []struct {
+		Company string          `json:"company"`
+		Role    string          `json:"role"`
+		Status  json.RawMessage `json:"status"` // A status name or its number.
+		Notes   []string        `json:"notes"`
+	}
+
+	err := json.NewDecoder(body).Decode(&raw_rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0, len(raw_rows))
+	for _, raw_row := range raw_rows {
+		status := strings.Trim(string(raw_row.Status), `"`)
+		if status == "null" {
+			status = ""
+		}
+
+		notes := raw_row.Notes
+		if notes == nil {
+			notes = []string{}
+		}
+
+		rows = append(rows, importRow{
+			company: strings.TrimSpace(raw_row.Company),
+			role:    strings.TrimSpace(raw_row.Role),
+			status:  status,
+			notes:   notes,
+		})
+	}
+
+	return rows, nil
+}
+
+func (row importRow) toJobApplication() (*store.JobApplication, error) {
+	status := store.Active
+	if row.status != "" {
+		parsed_status, err := store.ParseApplicationStatus(row.status)
+		if err != nil {
+			return nil, err
+		}
+		status = parsed_status
+	}
+
+	return store.NewJobApplication(row.company, store.JobRole(row.role), status, row.notes)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Posts an import and returns the report along with how many applications were actually saved.
+func importRequest(t *testing.T, url string, content_type string, body string) (importReport, int) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	request := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	request.Header.Set("Content-Type", content_type)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	var report importReport
+	err = json.NewDecoder(response.Body).Decode(&report)
+	if err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	applications, err := app.DB.ListApplications(request.Context(), "testuser", store.ApplicationFilter{})
+	if err != nil {
+		t.Fatalf("Failed to list applications: %v", err)
+	}
+
+	return report, len(applications) - 2 // setupTestApp starts with two.
+}
+
+func TestImportApplicationsCSV(t *testing.T) {
+	csv := "Employer,Position,State,Comments\n" +
+		"New Company,Software Engineer,Pending Response,Applied via referral\n" +
+		"Fake Company,Software Engineer,Active,\n" +
+		"Bad Role Inc.,Astronaut,Active,\n" +
+		"Another New Company,Software Engineer,,\n"
+
+	report, saved := importRequest(t, "/applications/import?company=Employer&role=Position&status=State&notes=Comments", "text/csv", csv)
+
+	expected := []store.ImportOutcome{store.ImportCreated, store.ImportSkipped, store.ImportFailed, store.ImportCreated}
+	if len(report.Rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(report.Rows))
+	}
+	for i, outcome := range expected {
+		if report.Rows[i].Result != outcome {
+			t.Errorf("Row %d: expected %q, got %q (%s)", i+1, outcome, report.Rows[i].Result, report.Rows[i].Reason)
+		}
+	}
+	if report.Rows[2].Reason == "" {
+		t.Errorf("Expected a reason for the failed row")
+	}
+	if report.Created != 2 || report.Skipped != 1 || report.Failed != 1 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if saved != 2 {
+		t.Errorf("Expected 2 applications to be saved, got %d", saved)
+	}
+}
+
+func TestImportApplicationsJSONDryRun(t *testing.T) {
+	body := `[
+		{"company": "New Company", "role": "Software Engineer", "status": "Offer", "notes": ["Great team."]},
+		{"company": "Second New Company", "role": "Software Engineer", "status": 1},
+		{"company": "", "role": "Software Engineer"}
+	]`
+
+	report, saved := importRequest(t, "/applications/import?dry_run=true", "application/json", body)
+
+	if !report.DryRun {
+		t.Errorf("Expected dry_run in report")
+	}
+	if report.Rows[0].Result != store.ImportCreated || report.Rows[1].Result != store.ImportCreated || report.Rows[2].Result != store.ImportFailed {
+		t.Errorf("Unexpected row results: %+v", report.Rows)
+	}
+	if report.Created != 2 {
+		t.Errorf("Expected dry run to report 2 created, got %d", report.Created)
+	}
+	if saved != 0 {
+		t.Errorf("Expected dry run not to save anything, %d applications were saved", saved)
+	}
+}
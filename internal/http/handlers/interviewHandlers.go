@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	defaultUpcomingInterviews int = 20
+	maxUpcomingInterviews     int = 100
+)
+
+func interviewIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "interviewID"), 10, 64)
+}
+
+func (app *App) ListInterviews(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	interviews, err := app.DB.ListInterviews(request.Context(), username, store.InterviewFilter{Company: companyID})
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, interviews)
+}
+
+// Lists pending interviews from now on across every application, soonest first. `?limit=` defaults to 20.
+func (app *App) ListUpcomingInterviews(response_writer http.ResponseWriter, request *http.Request) {
+	limit := defaultUpcomingInterviews
+	if raw_limit := request.URL.Query().Get("limit"); raw_limit != "" {
+		parsed_limit, err := strconv.Atoi(raw_limit)
+		if err != nil || parsed_limit < 1 || parsed_limit > maxUpcomingInterviews {
+			http.Error(response_writer, "limit must be between 1 and "+strconv.Itoa(maxUpcomingInterviews), http.StatusBadRequest)
+			return
+		}
+		limit = parsed_limit
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	interviews, err := app.DB.ListInterviews(request.Context(), username, store.InterviewFilter{
+		ScheduledAfter: time.Now(),
+		Outcome:        store.InterviewPending,
+		Limit:          limit,
+	})
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, interviews)
+}
+
+func (app *App) GetInterview(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	interviewID, err := interviewIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid interview ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	interview, err := app.DB.GetInterview(request.Context(), username, companyID, interviewID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, interview)
+}
+
+// Schedules an interview and responds with it, including its new ID.
+//
+// Unless the body sets `"advance_status": false`, an application pending a response becomes Active.
+func (app *App) CreateInterview(response_writer http.ResponseWriter, request *http.Request) {
+	var interview_request struct {
+		store.Interview
+		AdvanceStatus *bool `json:"advance_status"`
+	}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&interview_request)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interview := &interview_request.Interview
+	interview.ID = 0
+	interview.Company = chi.URLParam(request, "companyID")
+
+	err = interview.Validate()
+	if err != nil {
+		http.Error(response_writer, "invalid interview: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	advance_status := interview_request.AdvanceStatus == nil || *interview_request.AdvanceStatus
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateInterview(request.Context(), username, interview, advance_status)
+	if err != nil {
+		writeStoreError(response_writer, "DB insert failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, interview)
+}
+
+// Replaces every field of an interview, such as to record its outcome and feedback.
+func (app *App) UpdateInterview(response_writer http.ResponseWriter, request *http.Request) {
+	interviewID, err := interviewIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid interview ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interview := &store.Interview{}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(interview)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interview.ID = interviewID
+	interview.Company = chi.URLParam(request, "companyID")
+
+	err = interview.Validate()
+	if err != nil {
+		http.Error(response_writer, "invalid interview: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateInterview(request.Context(), username, interview)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, interview)
+}
+
+func (app *App) DeleteInterview(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	interviewID, err := interviewIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid interview ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.DeleteInterview(request.Context(), username, companyID, interviewID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func TestScheduleInterviewAdvancesStatus(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/interviews",
+		`{"type": "phone_screen", "scheduled_at": "2030-01-15T15:00:00Z", "timezone": "Europe/Paris", "duration_minutes": 30, "interviewers": ["Jane Doe"]}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+
+	var interview store.Interview
+	err = json.Unmarshal([]byte(body), &interview)
+	if err != nil {
+		t.Fatalf("Failed to decode interview: %v", err)
+	}
+	if interview.ID == 0 || !strings.Contains(body, `"scheduled_at":"2030-01-15T16:00:00+01:00"`) {
+		t.Errorf("Expected the saved interview in its time zone, got %s", body)
+	}
+
+	application, err := app.DB.GetApplication(t.Context(), "testuser", "Another Fake Company")
+	if err != nil {
+		t.Fatalf("Failed to get application: %v", err)
+	}
+	if application.GetStatus() != store.Active {
+		t.Errorf("Expected scheduling to advance the status to Active, got %v", application.GetStatus())
+	}
+}
+
+func TestScheduleInterviewWithoutAdvancingStatus(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/interviews",
+		`{"type": "technical", "scheduled_at": "2030-01-15T15:00:00Z", "advance_status": false}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d", http.StatusCreated, status)
+	}
+
+	application, _ := app.DB.GetApplication(t.Context(), "testuser", "Another Fake Company")
+	if application.GetStatus() != store.PendingResponse {
+		t.Errorf("Expected the status to be left alone, got %v", application.GetStatus())
+	}
+}
+
+func TestScheduleInterviewInvalid(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/interviews", `{"type": "coffee", "scheduled_at": "2030-01-15T15:00:00Z"}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for a bad type, got %d", http.StatusBadRequest, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Nobody/interviews", `{"type": "onsite", "scheduled_at": "2030-01-15T15:00:00Z"}`)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing application, got %d", http.StatusNotFound, status)
+	}
+}
+
+func TestRecordInterviewOutcome(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/interviews", `{"type": "onsite", "scheduled_at": "2030-01-15T15:00:00Z"}`)
+
+	status, body := sendRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company/interviews/1",
+		`{"type": "onsite", "scheduled_at": "2030-01-15T15:00:00Z", "outcome": "passed", "feedback": "Strong system design."}`)
+	if status != http.StatusOK || !strings.Contains(body, `"outcome":"passed"`) {
+		t.Fatalf("Unexpected update response %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPut, "/applications/Another%20Fake%20Company/interviews/1", `{"type": "onsite", "scheduled_at": "2030-01-15T15:00:00Z"}`)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d updating through another application, got %d", http.StatusNotFound, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company/interviews/1", "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestListUpcomingInterviews(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	later := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	sooner := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/interviews", `{"type": "onsite", "scheduled_at": "`+later+`"}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/interviews", `{"type": "technical", "scheduled_at": "`+sooner+`"}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/interviews", `{"type": "phone_screen", "scheduled_at": "`+past+`"}`)
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/interviews/upcoming", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var interviews []store.Interview
+	err = json.Unmarshal([]byte(body), &interviews)
+	if err != nil {
+		t.Fatalf("Failed to decode interviews: %v", err)
+	}
+	if len(interviews) != 2 {
+		t.Fatalf("Expected 2 upcoming interviews, got %d", len(interviews))
+	}
+	if interviews[0].Company != "Another Fake Company" || interviews[1].Company != "Fake Company" {
+		t.Errorf("Expected the soonest interview first, got %s then %s", interviews[0].Company, interviews[1].Company)
+	}
+}
+
+func TestExportInterviewsAsCalendarEvents(t *testing.T) {
+	_, body := exportRequest(t, "/applications/export?format=ics", func(app *App) {
+		interview := &store.Interview{Company: "Fake Company", Type: store.TechnicalInterview, ScheduledAt: time.Date(2030, 1, 15, 15, 0, 0, 0, time.UTC), VideoLink: "https://meet.example.com/abc"}
+		_ = interview.Validate()
+		err := app.DB.CreateInterview(t.Context(), "testuser", interview, false)
+		if err != nil {
+			t.Fatalf("Failed to create interview: %v", err)
+		}
+	})
+
+	for _, line := range []string{"DTSTART:20300115T150000Z\r\n", "DTEND:20300115T160000Z\r\n", "SUMMARY:technical interview with Fake Company\r\n", "LOCATION:https://meet.example.com/abc\r\n"} {
+		if !strings.Contains(body, line) {
+			t.Errorf("Missing %q in:\n%s", line, body)
+		}
+	}
+}
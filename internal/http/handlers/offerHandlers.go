@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func offerIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "offerID"), 10, 64)
+}
+
+// Decodes and validates an offer from the request body, for the application in the URL.
+func decodeOffer(request *http.Request) (*store.JobOffer, error) {
+	offer := &store.JobOffer{}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(offer)
+	if err != nil {
+		return nil, err
+	}
+
+	offer.ID = 0
+	offer.Company = chi.URLParam(request, "companyID")
+	return offer, offer.Validate()
+}
+
+func (app *App) ListOffers(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	offers, err := app.DB.ListOffers(request.Context(), username, companyID)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, offers)
+}
+
+func (app *App) GetOffer(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	offerID, err := offerIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid offer ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	offer, err := app.DB.GetOffer(request.Context(), username, companyID, offerID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, offer)
+}
+
+// Records an offer and responds with it, including its new ID.
+func (app *App) CreateOffer(response_writer http.ResponseWriter, request *http.Request) {
+	offer, err := decodeOffer(request)
+	if err != nil {
+		http.Error(response_writer, "invalid offer: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateOffer(request.Context(), username, offer)
+	if err != nil {
+		writeStoreError(response_writer, "DB insert failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, offer)
+}
+
+// Replaces every field of an offer.
+func (app *App) UpdateOffer(response_writer http.ResponseWriter, request *http.Request) {
+	offerID, err := offerIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid offer ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offer, err := decodeOffer(request)
+	if err != nil {
+		http.Error(response_writer, "invalid offer: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	offer.ID = offerID
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateOffer(request.Context(), username, offer)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, offer)
+}
+
+func (app *App) DeleteOffer(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	offerID, err := offerIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid offer ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.DeleteOffer(request.Context(), username, companyID, offerID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+type offerComparison struct {
+	Offer        *store.JobOffer `json:"offer"`
+	AnnualBase   float64         `json:"annual_base"`
+	AnnualBonus  float64         `json:"annual_bonus"`
+	AnnualEquity float64         `json:"annual_equity"`
+	AnnualTotal  float64         `json:"annual_total"`
+}
+
+type skippedOffer struct {
+	ID      int64  `json:"id"`
+	Company string `json:"company"`
+	Reason  string `json:"reason"`
+}
+
+type offerComparisonResponse struct {
+	Currency string            `json:"currency"`
+	Offers   []offerComparison `json:"offers"`  // Highest annual total first.
+	Skipped  []skippedOffer    `json:"skipped"` // Offers in currencies missing from the rate table.
+}
+
+// Compares offers side by side as yearly compensation, converted with the configured static exchange rates.
+//
+// `?currency=` picks the currency to compare in, defaulting to offers.base_currency.
+// `?company=` may be repeated to compare only those applications' offers.
+func (app *App) CompareOffers(response_writer http.ResponseWriter, request *http.Request) {
+	currency := strings.ToUpper(request.URL.Query().Get("currency"))
+	if currency == "" {
+		currency = app.Config.Offers.BaseCurrency
+	}
+	if _, ok := app.Config.Offers.ExchangeRates[currency]; !ok {
+		http.Error(response_writer, "no exchange rate for "+currency, http.StatusBadRequest)
+		return
+	}
+
+	companies := request.URL.Query()["company"]
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	offers, err := app.DB.ListOffers(request.Context(), username, "")
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := offerComparisonResponse{
+		Currency: currency,
+		Offers:   []offerComparison{},
+		Skipped:  []skippedOffer{},
+	}
+
+	for _, offer := range offers {
+		if len(companies) > 0 && !slices.Contains(companies, offer.Company) {
+			continue
+		}
+
+		annual := offer.Annualised()
+		converted := [4]float64{annual.Base, annual.Bonus, annual.Equity, annual.Total}
+
+		for i, amount := range converted {
+			converted[i], err = app.Config.Offers.Convert(amount, offer.Currency, currency)
+			if err != nil {
+				break
+			}
+			converted[i] = math.Round(converted[i]*100) / 100
+		}
+		if err != nil {
+			response.Skipped = append(response.Skipped, skippedOffer{offer.ID, offer.Company, err.Error()})
+			continue
+		}
+
+		response.Offers = append(response.Offers, offerComparison{offer, converted[0], converted[1], converted[2], converted[3]})
+	}
+
+	sort.SliceStable(response.Offers, func(i, j int) bool {
+		return response.Offers[i].AnnualTotal > response.Offers[j].AnnualTotal
+	})
+
+	writeJSON(response_writer, http.StatusOK, response)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOffersCRUD(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/offers",
+		`{"currency": "usd", "base_salary": 120000, "bonus": 10000, "start_date": "2025-09-01", "expires_on": "2025-07-15", "benefits": "Private healthcare."}`)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+	if !strings.Contains(body, `"currency":"USD"`) || !strings.Contains(body, `"start_date":"2025-09-01"`) {
+		t.Errorf("Unexpected created offer %s", body)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodPut, "/applications/Fake%20Company/offers/1", `{"currency": "USD", "base_salary": 125000}`)
+	if status != http.StatusOK || !strings.Contains(body, `"base_salary":125000`) {
+		t.Fatalf("Unexpected update response %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Nobody/offers", `{"currency": "USD", "base_salary": 1}`)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing application, got %d", http.StatusNotFound, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/applications/Fake%20Company/offers/1", "")
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+}
+
+func TestCompareOffers(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+	app.Config.Offers.BaseCurrency = "USD"
+	app.Config.Offers.ExchangeRates = map[string]float64{"USD": 1, "GBP": 1.25}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/offers", `{"currency": "USD", "base_salary": 110000}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/offers", `{"currency": "GBP", "base_salary": 8000, "salary_period": "month", "equity": 20000}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/offers", `{"currency": "JPY", "base_salary": 9000000}`)
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/offers/compare", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, status, body)
+	}
+
+	var comparison offerComparisonResponse
+	err = json.Unmarshal([]byte(body), &comparison)
+	if err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+
+	if len(comparison.Offers) != 2 || len(comparison.Skipped) != 1 {
+		t.Fatalf("Expected 2 compared and 1 skipped offer, got %s", body)
+	}
+	// 8000 GBP a month plus 5000 GBP of equity a year, at 1.25 USD per GBP.
+	if comparison.Offers[0].Offer.Company != "Another Fake Company" || comparison.Offers[0].AnnualTotal != 126250 {
+		t.Errorf("Expected the GBP offer first at 126250 USD, got %+v", comparison.Offers[0])
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/offers/compare?currency=gbp&company=Fake%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"annual_total":88000`) {
+		t.Errorf("Expected the USD offer in GBP, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodGet, "/offers/compare?currency=XYZ", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown currency, got %d", http.StatusBadRequest, status)
+	}
+}
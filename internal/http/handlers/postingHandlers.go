@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/medidew/ApplicationTracker/internal/posting"
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const maxPostingBytes int64 = 5 << 20
+
+type fromPostingRequest struct {
+	URL  string `json:"url"`
+	HTML string `json:"html"` // The page as saved by the browser, so nothing is fetched server-side.
+
+	// Override or fill in what the page provides.
+	Company string                  `json:"company"`
+	Role    store.JobRole           `json:"role"`
+	Status  store.ApplicationStatus `json:"status"`
+}
+
+type fromPostingResponse struct {
+	Application *store.JobApplication `json:"application"`
+	Posting     *posting.Posting      `json:"posting"`
+}
+
+// Creates an application from a saved job advert, pre-filled with what can be read from its
+// JSON-LD or OpenGraph metadata. The extracted details are returned alongside the application.
+func (app *App) CreateApplicationFromPosting(response_writer http.ResponseWriter, request *http.Request) {
+	request.Body = http.MaxBytesReader(response_writer, request.Body, maxPostingBytes)
+
+	from_posting := fromPostingRequest{Role: store.SoftwareEngineer}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&from_posting)
+	if err != nil {
+		var max_bytes_err *http.MaxBytesError
+		if errors.As(err, &max_bytes_err) {
+			http.Error(response_writer, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if from_posting.URL == "" {
+		http.Error(response_writer, "`url` is required", http.StatusBadRequest)
+		return
+	}
+
+	job_posting, err := posting.Parse(strings.NewReader(from_posting.HTML))
+	if err != nil {
+		http.Error(response_writer, "failed to parse posting: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	company := strings.TrimSpace(from_posting.Company)
+	if company == "" {
+		company = job_posting.Company
+	}
+	if company == "" {
+		http.Error(response_writer, "could not find the company in the posting; set `company`", http.StatusUnprocessableEntity)
+		return
+	}
+
+	details := postingDetails(job_posting)
+
+	notes := []string{}
+	summary := postingSummary(job_posting, details.Salary == nil)
+	if summary != "" {
+		notes = append(notes, summary)
+	}
+
+	job_application, err := store.NewJobApplication(company, from_posting.Role, from_posting.Status, notes, details)
+	if err != nil {
+		http.Error(response_writer, "invalid application: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = job_application.SetPostingURL(from_posting.URL)
+	if err != nil {
+		http.Error(response_writer, "invalid application: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateApplication(request.Context(), username, job_application)
+	if err != nil {
+		writeCreateApplicationError(response_writer, job_application.GetCompany(), err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, fromPostingResponse{Application: job_application, Posting: job_posting})
+}
+
+// Maps what the advert says onto the application's typed fields. A salary is only kept if it
+// fits a SalaryRange, i.e. it has a currency and a supported period.
+func postingDetails(job_posting *posting.Posting) store.ApplicationDetails {
+	details := store.ApplicationDetails{Location: job_posting.Location}
+
+	if job_posting.Remote {
+		details.RemotePolicy = store.Remote
+	}
+
+	if job_posting.Salary != nil {
+		salary := &store.SalaryRange{
+			Min:      job_posting.Salary.Min,
+			Max:      job_posting.Salary.Max,
+			Currency: job_posting.Salary.Currency,
+			Period:   store.PayPeriod(job_posting.Salary.Period),
+		}
+		if salary.Validate() == nil {
+			details.Salary = salary
+		}
+	}
+
+	return details
+}
+
+// Describes the advert in one line for the application's notes, e.g. "Posting: Senior Engineer",
+// with the salary as text if it couldn't be kept as a SalaryRange. Empty if there's nothing to say.
+func postingSummary(job_posting *posting.Posting, include_salary bool) string {
+	parts := []string{}
+
+	if job_posting.Title != "" {
+		parts = append(parts, job_posting.Title)
+	}
+	if include_salary && job_posting.Salary != nil {
+		salary := fmt.Sprintf("%g", job_posting.Salary.Min)
+		if job_posting.Salary.Max != job_posting.Salary.Min {
+			salary += fmt.Sprintf("-%g", job_posting.Salary.Max)
+		}
+		if job_posting.Salary.Currency != "" {
+			salary = job_posting.Salary.Currency + " " + salary
+		}
+		if job_posting.Salary.Period != "" {
+			salary += " per " + job_posting.Salary.Period
+		}
+		parts = append(parts, salary)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "Posting: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+const testPostingHTML = `<html><head>
+<title>Careers</title>
+<script type="application/ld+json">{
+	"@context": "https://schema.org", "@type": "JobPosting",
+	"title": "Backend Engineer",
+	"hiringOrganization": {"@type": "Organization", "name": "Posting Co"},
+	"jobLocation": {"@type": "Place", "address": {"addressLocality": "Leeds", "addressCountry": "GB"}},
+	"baseSalary": {"currency": "GBP", "value": {"minValue": 50000, "maxValue": 60000, "unitText": "YEAR"}}
+}</script>
+</head><body></body></html>`
+
+func TestCreateApplicationFromPosting(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	request_body, _ := json.Marshal(map[string]string{"url": "https://jobs.example.com/backend", "html": testPostingHTML})
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications/from-posting", string(request_body))
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, body)
+	}
+
+	var created struct {
+		Application map[string]any `json:"application"`
+		Posting     map[string]any `json:"posting"`
+	}
+	err = json.Unmarshal([]byte(body), &created)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if created.Application["company"] != "Posting Co" || created.Application["posting_url"] != "https://jobs.example.com/backend" {
+		t.Errorf("Unexpected application %v", created.Application)
+	}
+	if created.Posting["title"] != "Backend Engineer" || created.Posting["location"] != "Leeds, GB" {
+		t.Errorf("Unexpected posting %v", created.Posting)
+	}
+
+	job_application, err := app.DB.GetApplication(t.Context(), "testuser", "Posting Co")
+	if err != nil {
+		t.Fatalf("Failed to get application: %v", err)
+	}
+	details := job_application.GetDetails()
+	if details.Location != "Leeds, GB" || details.Salary == nil || details.Salary.Min != 50000 || details.Salary.Currency != "GBP" {
+		t.Errorf("Unexpected details %+v", details)
+	}
+
+	notes, _ := app.DB.ListApplicationNotes(t.Context(), "testuser", "Posting Co")
+	if len(notes) != 1 || notes[0] != "Posting: Backend Engineer" {
+		t.Errorf("Unexpected notes %q", notes)
+	}
+}
+
+func TestCreateApplicationFromPostingInvalid(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"missing url", `{"html": "<html></html>", "company": "Somewhere"}`, http.StatusBadRequest},
+		{"relative url", `{"url": "/jobs/1", "html": "<html></html>", "company": "Somewhere"}`, http.StatusBadRequest},
+		{"no company found", `{"url": "https://example.com/jobs/1", "html": "<html><title>Job</title></html>"}`, http.StatusUnprocessableEntity},
+		{"company given", `{"url": "https://example.com/jobs/1", "html": "<html><title>Job</title></html>", "company": "Somewhere"}`, http.StatusCreated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := sendRequest(t, app, router, token, http.MethodPost, "/applications/from-posting", test.body)
+			if status != test.expected {
+				t.Errorf("Expected status code %d, got %d: %s", test.expected, status, body)
+			}
+		})
+	}
+}
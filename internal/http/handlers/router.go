@@ -1,10 +1,9 @@
 package handlers
 
 import (
-	"net/http"
-
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/medidew/ApplicationTracker/internal/http/middleware"
 )
@@ -12,38 +11,135 @@ import (
 func SetupRouter(app *App) *chi.Mux {
 	router := chi.NewRouter()
 
+	router.Use(middleware.RequestIDMiddleware)
+	router.Use(middleware.TracingMiddleware(app.TracerProvider))
 	router.Use(app.SessionManager.LoadAndSave)
-	router.Use(middleware.ZapLoggerMiddleware(app.Logger))
+	router.Use(middleware.ZapLoggerMiddleware(app.Logger, app.SessionManager))
+	router.Use(middleware.MetricsMiddleware(app.Metrics))
+	router.Use(middleware.RecoverMiddleware(app.Logger, app.Metrics))
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"},
+		AllowedOrigins:   app.Config.CORS.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", IdempotencyKeyHeader, middleware.RequestIDHeader},
+		ExposedHeaders:   []string{"Link", "Content-Disposition", "Repr-Digest", "ETag", IdempotentReplayedHeader, middleware.RequestIDHeader},
 		AllowCredentials: true,
-		MaxAge:           300,
+		MaxAge:           app.Config.CORS.MaxAge,
 	}))
 
-	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
-
-	})
+	router.Get("/healthz", app.Healthz)
+	router.Get("/readyz", app.Readyz)
+	router.Get("/version", app.Version)
+	router.Handle("/metrics", promhttp.HandlerFor(app.Metrics.Registry, promhttp.HandlerOpts{}))
 
 	router.Route("/applications", func(router chi.Router) {
 		router.Get("/", app.ListApplications)
-		router.Post("/", app.CreateApplication)
+		router.With(app.Idempotent).Post("/", app.CreateApplication)
+		router.With(app.Idempotent).Post("/import", app.ImportApplications)
+		router.With(app.Idempotent).Post("/batch", app.BatchApplications)
+		router.Post("/from-posting", app.CreateApplicationFromPosting)
+		router.Get("/export", app.ExportApplications)
+		router.Get("/search", app.SearchApplications)
+		router.Get("/trash", app.ListTrashedApplications)
 
 		router.Route("/{companyID}", func(router chi.Router) {
 			router.Get("/", app.GetApplication)
 			router.Delete("/", app.DeleteApplication)
+			router.Post("/restore", app.RestoreApplication)
+			router.Post("/archive", app.ArchiveApplication)
+			router.Post("/unarchive", app.UnarchiveApplication)
 			router.Put("/", app.UpdateApplicationStatus)
+			router.Put("/follow-up", app.SetApplicationFollowUp)
+			router.Put("/details", app.UpdateApplicationDetails)
+			router.Put("/custom-fields", app.UpdateApplicationCustomFields)
 
 			router.Route("/notes", func(router chi.Router) {
 				router.Get("/", app.ListApplicationNotes)
-				router.Post("/", app.AddApplicationNote)
+				router.With(app.Idempotent).Post("/", app.AddApplicationNote)
 				router.Delete("/{noteIndex}", app.RemoveApplicationNote)
 			})
+
+			router.Route("/contacts", func(router chi.Router) {
+				router.Get("/", app.ListApplicationContacts)
+				router.Post("/", app.LinkApplicationContact)
+				router.Delete("/{contactID}", app.UnlinkApplicationContact)
+			})
+
+			router.Route("/tags", func(router chi.Router) {
+				router.Get("/", app.ListApplicationTags)
+				router.Post("/", app.TagApplication)
+				router.Delete("/{tagID}", app.UntagApplication)
+			})
+
+			router.Route("/interviews", func(router chi.Router) {
+				router.Get("/", app.ListInterviews)
+				router.Post("/", app.CreateInterview)
+
+				router.Route("/{interviewID}", func(router chi.Router) {
+					router.Get("/", app.GetInterview)
+					router.Put("/", app.UpdateInterview)
+					router.Delete("/", app.DeleteInterview)
+				})
+			})
+
+			router.Route("/offers", func(router chi.Router) {
+				router.Get("/", app.ListOffers)
+				router.Post("/", app.CreateOffer)
+
+				router.Route("/{offerID}", func(router chi.Router) {
+					router.Get("/", app.GetOffer)
+					router.Put("/", app.UpdateOffer)
+					router.Delete("/", app.DeleteOffer)
+				})
+			})
+
+			router.Route("/attachments", func(router chi.Router) {
+				router.Get("/", app.ListAttachments)
+				router.Post("/", app.UploadAttachment)
+
+				router.Route("/{attachmentID}", func(router chi.Router) {
+					router.Get("/", app.DownloadAttachment)
+					router.Delete("/", app.DeleteAttachment)
+				})
+			})
+		})
+	})
+
+	router.Route("/contacts", func(router chi.Router) {
+		router.Get("/", app.ListContacts)
+		router.Post("/", app.CreateContact)
+
+		router.Route("/{contactID}", func(router chi.Router) {
+			router.Get("/", app.GetContact)
+			router.Put("/", app.UpdateContact)
+			router.Delete("/", app.DeleteContact)
+		})
+	})
+
+	router.Route("/custom-fields", func(router chi.Router) {
+		router.Get("/", app.ListCustomFields)
+		router.Post("/", app.CreateCustomField)
+
+		router.Route("/{fieldID}", func(router chi.Router) {
+			router.Put("/", app.UpdateCustomField)
+			router.Delete("/", app.DeleteCustomField)
 		})
 	})
 
+	router.Route("/tags", func(router chi.Router) {
+		router.Get("/", app.ListTags)
+		router.Post("/", app.CreateTag)
+
+		router.Route("/{tagID}", func(router chi.Router) {
+			router.Get("/", app.GetTag)
+			router.Put("/", app.UpdateTag)
+			router.Delete("/", app.DeleteTag)
+		})
+	})
+
+	router.Get("/interviews/upcoming", app.ListUpcomingInterviews)
+	router.Get("/offers/compare", app.CompareOffers)
+	router.Get("/stats", app.GetStats)
+
 	router.Post("/register", app.Register)
 	router.Post("/login", app.Login)
 	router.Get("/logout", app.Logout)
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/medidew/ApplicationTracker/internal/tracing"
+)
+
+func TestMetricsUseRoutePattern(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/applications/Fake%20Company", nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+
+	expected := `apptracker_http_requests_total{method="GET",route="/applications/{companyID}",status="200"} 1`
+	if !strings.Contains(string(body), expected) {
+		t.Fatalf("Expected metrics to contain %q, got:\n%s", expected, body)
+	}
+	if strings.Contains(string(body), "Fake Company") || strings.Contains(string(body), "Fake%20Company") {
+		t.Fatalf("Expected raw path not to appear in metrics")
+	}
+}
+
+func TestRequestIDEchoed(t *testing.T) {
+	app := setupTestApp()
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	request.Header.Set("X-Request-ID", "upstream-id-123")
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	if request_id := response_recorder.Header().Get("X-Request-ID"); request_id != "upstream-id-123" {
+		t.Fatalf("Expected incoming request ID to be echoed, got %q", request_id)
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	app := setupTestApp()
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	request.Header.Set("X-Request-ID", "not\nsafe")
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	request_id := response_recorder.Header().Get("X-Request-ID")
+	if request_id == "" || request_id == "not\nsafe" {
+		t.Fatalf("Expected a generated request ID, got %q", request_id)
+	}
+}
+
+func TestAccessLogFields(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	observed_core, observed_logs := observer.New(zap.InfoLevel)
+	app.Logger = zap.New(observed_core)
+	router = setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/applications", nil)
+	request.Header.Set("X-Request-ID", "access-log-test")
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	entries := observed_logs.FilterMessage("Request complete").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 access log entry, got %d", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "access-log-test" {
+		t.Errorf("Expected request_id %q, got %v", "access-log-test", fields["request_id"])
+	}
+	if fields["username"] != "testuser" {
+		t.Errorf("Expected username %q, got %v", "testuser", fields["username"])
+	}
+	if fields["route"] != "/applications" {
+		t.Errorf("Expected route %q, got %v", "/applications", fields["route"])
+	}
+	if _, ok := fields["duration"]; !ok {
+		t.Errorf("Expected duration field")
+	}
+	if bytes, ok := fields["bytes"].(int64); !ok || bytes == 0 {
+		t.Errorf("Expected non-zero bytes field, got %v", fields["bytes"])
+	}
+}
+
+func TestTracingSpansNamedByRoute(t *testing.T) {
+	app, _, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	span_recorder := tracetest.NewSpanRecorder()
+	app.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(span_recorder))
+	app.SessionManager.Store = tracing.NewSessionStore(app.SessionManager.Store, app.TracerProvider)
+	router := setupTestRouter(app)
+
+	request := httptest.NewRequest(http.MethodGet, "/applications", nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range span_recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	server_span, ok := spans["GET /applications"]
+	if !ok {
+		t.Fatalf("Expected a server span named by route pattern, got %v", spans)
+	}
+
+	for _, name := range []string{"session find", "marshal applications"} {
+		child_span, ok := spans[name]
+		if !ok {
+			t.Fatalf("Expected a %q span", name)
+		}
+		if child_span.Parent().SpanID() != server_span.SpanContext().SpanID() {
+			t.Errorf("Expected %q to be a child of the server span", name)
+		}
+	}
+}
+
+func TestRecoverFromPanic(t *testing.T) {
+	app := setupTestApp()
+
+	observed_core, observed_logs := observer.New(zap.InfoLevel)
+	app.Logger = zap.New(observed_core)
+
+	router := SetupRouter(app)
+	router.Get("/panic", func(response_writer http.ResponseWriter, request *http.Request) {
+		panic("secret internal detail")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	request.Header.Set("X-Request-ID", "panic-test")
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %d", http.StatusInternalServerError, response.StatusCode)
+	}
+	if content_type := response.Header.Get("Content-Type"); content_type != "application/problem+json" {
+		t.Errorf("Expected problem+json response, got %q", content_type)
+	}
+
+	var problem struct {
+		Status    int    `json:"status"`
+		RequestID string `json:"request_id"`
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	if strings.Contains(string(body), "secret internal detail") {
+		t.Errorf("Expected panic value not to leak to the client")
+	}
+	err = json.Unmarshal(body, &problem)
+	if err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	} else if problem.Status != http.StatusInternalServerError || problem.RequestID != "panic-test" {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	entries := observed_logs.FilterMessage("Handler panicked").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 panic log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "panic-test" || fields["panic"] != "secret internal detail" {
+		t.Errorf("Unexpected panic log fields: %v", fields)
+	}
+	if stack, ok := fields["stack"].(string); !ok || !strings.Contains(stack, "TestRecoverFromPanic") {
+		t.Errorf("Expected panic log to include the stack")
+	}
+
+	if panics := testutil.ToFloat64(app.Metrics.Panics.WithLabelValues("/panic")); panics != 1 {
+		t.Errorf("Expected panics metric 1, got %v", panics)
+	}
+	if requests := testutil.ToFloat64(app.Metrics.HTTPRequests.WithLabelValues("GET", "/panic", "500")); requests != 1 {
+		t.Errorf("Expected panicking request to be counted as a 500, got %v", requests)
+	}
+}
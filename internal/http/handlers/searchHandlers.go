@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	defaultSearchLimit int = 20
+	maxSearchLimit     int = 100
+)
+
+type searchResult struct {
+	Application *store.JobApplication `json:"application"`
+	Rank        float64               `json:"rank"`
+	Snippet     string                `json:"snippet"` // HTML, with matches wrapped in <mark> and the rest escaped.
+}
+
+// Full-text search over the user's companies, roles and notes with `?q=`, best matches first.
+// `?limit=` caps the results, 20 by default and at most 100.
+func (app *App) SearchApplications(response_writer http.ResponseWriter, request *http.Request) {
+	query := strings.TrimSpace(request.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(response_writer, "missing search query q", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if raw_limit := request.URL.Query().Get("limit"); raw_limit != "" {
+		parsed_limit, err := strconv.Atoi(raw_limit)
+		if err != nil || parsed_limit < 1 || parsed_limit > maxSearchLimit {
+			http.Error(response_writer, "limit must be between 1 and "+strconv.Itoa(maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed_limit
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	results, err := app.DB.SearchApplications(request.Context(), username, query, limit)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]searchResult, len(results))
+	for i, result := range results {
+		response[i] = searchResult{result.Application, result.Rank, result.Snippet}
+	}
+
+	writeJSON(response_writer, http.StatusOK, response)
+}
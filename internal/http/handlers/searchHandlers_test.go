@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func searchRequest(t *testing.T, url string) (*http.Response, []map[string]any) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	err = app.DB.AddApplicationNote(t.Context(), "testuser", "Another Fake Company", "Recruiter mentioned Kubernetes and Go.", 0)
+	if err != nil {
+		t.Fatalf("Failed to add note: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	var results []map[string]any
+	if response.StatusCode == http.StatusOK {
+		err = json.NewDecoder(response.Body).Decode(&results)
+		if err != nil {
+			t.Fatalf("Failed to decode results: %v", err)
+		}
+	}
+
+	return response, results
+}
+
+func TestSearchApplications(t *testing.T) {
+	response, results := searchRequest(t, "/applications/search?q=kubernetes")
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	application := results[0]["application"].(map[string]any)
+	if application["company"] != "Another Fake Company" {
+		t.Errorf("Unexpected match %v", application["company"])
+	}
+	if !strings.Contains(results[0]["snippet"].(string), "<mark>Kubernetes</mark>") {
+		t.Errorf("Expected the match to be highlighted, got %q", results[0]["snippet"])
+	}
+}
+
+func TestSearchApplicationsEscapesSnippet(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	err = app.DB.AddApplicationNote(t.Context(), "testuser", "Fake Company", `<img src=x onerror="alert(1)"> Kubernetes`, 0)
+	if err != nil {
+		t.Fatalf("Failed to add note: %v", err)
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodGet, "/applications/search?q=kubernetes", "")
+	if status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+
+	var results []map[string]any
+	err = json.Unmarshal([]byte(body), &results)
+	if err != nil {
+		t.Fatalf("Failed to decode results: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	snippet := results[0]["snippet"].(string)
+	if strings.Contains(snippet, "<img") || !strings.Contains(snippet, "&lt;img") || !strings.Contains(snippet, "<mark>Kubernetes</mark>") {
+		t.Errorf("Expected the note escaped with only the match marked up, got %q", snippet)
+	}
+}
+
+func TestSearchApplicationsRanksCompanyFirst(t *testing.T) {
+	_, results := searchRequest(t, "/applications/search?q=fake")
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0]["rank"].(float64) < results[1]["rank"].(float64) {
+		t.Errorf("Results are not ordered by rank: %v", results)
+	}
+}
+
+func TestSearchApplicationsRequiresEveryTerm(t *testing.T) {
+	_, results := searchRequest(t, "/applications/search?q=kubernetes+rust")
+
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestSearchApplicationsInvalid(t *testing.T) {
+	for _, url := range []string{"/applications/search", "/applications/search?q=go&limit=0", "/applications/search?q=go&limit=many"} {
+		response, _ := searchRequest(t, url)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", url, http.StatusBadRequest, response.StatusCode)
+		}
+	}
+}
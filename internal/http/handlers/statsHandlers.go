@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const (
+	defaultStatsWeeks int = 12
+	maxStatsWeeks     int = 104
+)
+
+type weeklyCount struct {
+	WeekStart string `json:"week_start"` // The Monday starting the week, as YYYY-MM-DD.
+	Count     int    `json:"count"`
+}
+
+type statsResponse struct {
+	Total                int            `json:"total"`
+	ByStatus             map[string]int `json:"by_status"`
+	ByRole               map[string]int `json:"by_role"`
+	Responded            int            `json:"responded"`
+	ResponseRate         float64        `json:"response_rate"`
+	OfferRate            float64        `json:"offer_rate"`
+	MedianDaysToResponse *float64       `json:"median_days_to_response"`
+	Weekly               []weeklyCount  `json:"weekly"`
+}
+
+// Dashboard aggregates over the user's applications.
+//
+// Weekly volume covers `?from=` to `?to=` (dates or RFC 3339 times), defaulting to the last 12 weeks.
+// Every status and supported role is listed, even at zero, so charts keep a stable shape.
+func (app *App) GetStats(response_writer http.ResponseWriter, request *http.Request) {
+	to, err := parseFilterTime(request.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(response_writer, "invalid to: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	from, err := parseFilterTime(request.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(response_writer, "invalid from: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -7*(defaultStatsWeeks-1))
+	}
+
+	if from.After(to) {
+		http.Error(response_writer, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+	if store.WeekStart(to).Sub(store.WeekStart(from)) >= time.Duration(maxStatsWeeks)*7*24*time.Hour {
+		http.Error(response_writer, "date range is limited to 104 weeks", http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	stats, err := app.DB.GetApplicationStats(request.Context(), username, from, to)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := statsResponse{
+		Total:                stats.Total,
+		ByStatus:             map[string]int{},
+		ByRole:               map[string]int{},
+		Responded:            stats.Responded,
+		ResponseRate:         stats.ResponseRate,
+		OfferRate:            stats.OfferRate,
+		MedianDaysToResponse: stats.MedianDaysToResponse,
+		Weekly:               make([]weeklyCount, len(stats.Weekly)),
+	}
+
+	for status := store.Active; status <= store.MaxStatus; status++ {
+		response.ByStatus[status.String()] = stats.ByStatus[status]
+	}
+	for _, role := range store.GetSupportedJobRoles() {
+		response.ByRole[string(role)] = 0
+	}
+	for role, count := range stats.ByRole {
+		response.ByRole[string(role)] = count
+	}
+	for i, week := range stats.Weekly {
+		response.Weekly[i] = weeklyCount{week.WeekStart.Format(time.DateOnly), week.Count}
+	}
+
+	writeJSON(response_writer, http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func statsRequest(t *testing.T, url string) (*http.Response, statsResponse) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	err = app.DB.UpdateApplicationStatus(t.Context(), "testuser", "Another Fake Company", store.Offer, 0)
+	if err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	request.AddCookie(&http.Cookie{Name: app.SessionManager.Cookie.Name, Value: token})
+	response_recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(response_recorder, request)
+
+	response := response_recorder.Result()
+	defer response.Body.Close()
+
+	var stats statsResponse
+	if response.StatusCode == http.StatusOK {
+		err = json.NewDecoder(response.Body).Decode(&stats)
+		if err != nil {
+			t.Fatalf("Failed to decode stats: %v", err)
+		}
+	}
+
+	return response, stats
+}
+
+func TestGetStats(t *testing.T) {
+	response, stats := statsRequest(t, "/stats?from=2025-01-01&to=2025-03-31")
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, response.StatusCode)
+	}
+	if stats.Total != 2 || stats.ByStatus["Offer"] != 1 || stats.ByStatus["Rejected"] != 0 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.ByRole["Software Engineer"] != 2 {
+		t.Errorf("Unexpected role counts: %v", stats.ByRole)
+	}
+	if stats.OfferRate != 0.5 || stats.ResponseRate != 0.5 {
+		t.Errorf("Unexpected rates: %v offer, %v response", stats.OfferRate, stats.ResponseRate)
+	}
+	if len(stats.Weekly) != 14 || stats.Weekly[0].WeekStart != "2024-12-30" {
+		t.Errorf("Unexpected weekly series: %v", stats.Weekly)
+	}
+}
+
+func TestGetStatsDefaultRange(t *testing.T) {
+	_, stats := statsRequest(t, "/stats")
+
+	if len(stats.Weekly) != defaultStatsWeeks {
+		t.Errorf("Expected %d weeks, got %d", defaultStatsWeeks, len(stats.Weekly))
+	}
+}
+
+func TestGetStatsInvalidRange(t *testing.T) {
+	for _, url := range []string{"/stats?from=2025-03-01&to=2025-01-01", "/stats?from=2020-01-01&to=2025-01-01", "/stats?to=soon"} {
+		response, _ := statsRequest(t, url)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", url, http.StatusBadRequest, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func tagIDParam(request *http.Request) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(request, "tagID"), 10, 64)
+}
+
+// Decodes and validates a tag from the request body. The ID is ignored, it comes from the URL or the DB.
+func decodeTag(request *http.Request) (*store.Tag, error) {
+	tag := &store.Tag{}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.ID = 0
+	return tag, tag.Validate()
+}
+
+func (app *App) ListTags(response_writer http.ResponseWriter, request *http.Request) {
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	tags, err := app.DB.ListTags(request.Context(), username)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, tags)
+}
+
+func (app *App) GetTag(response_writer http.ResponseWriter, request *http.Request) {
+	tagID, err := tagIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	tag, err := app.DB.GetTag(request.Context(), username, tagID)
+	if err != nil {
+		writeStoreError(response_writer, "DB query failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, tag)
+}
+
+// Creates a tag and responds with it, including its new ID.
+func (app *App) CreateTag(response_writer http.ResponseWriter, request *http.Request) {
+	tag, err := decodeTag(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.CreateTag(request.Context(), username, tag)
+	if err != nil {
+		writeStoreError(response_writer, "DB insert failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusCreated, tag)
+}
+
+// Renames or recolours a tag.
+func (app *App) UpdateTag(response_writer http.ResponseWriter, request *http.Request) {
+	tagID, err := tagIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tag, err := decodeTag(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tag.ID = tagID
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UpdateTag(request.Context(), username, tag)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, tag)
+}
+
+func (app *App) DeleteTag(response_writer http.ResponseWriter, request *http.Request) {
+	tagID, err := tagIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.DeleteTag(request.Context(), username, tagID)
+	if err != nil {
+		writeStoreError(response_writer, "DB delete failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) ListApplicationTags(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	username := app.SessionManager.GetString(request.Context(), "username")
+
+	tags, err := app.DB.ListApplicationTags(request.Context(), username, companyID)
+	if err != nil {
+		http.Error(response_writer, "DB query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(response_writer, http.StatusOK, tags)
+}
+
+// Tags the application with an existing tag, `{"tag_id": 1}`.
+// Tags don't change the application's version, so If-Match isn't checked.
+func (app *App) TagApplication(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+
+	var link struct {
+		TagID int64 `json:"tag_id"`
+	}
+
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&link)
+	if err != nil {
+		http.Error(response_writer, "failed to unmarshal: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.TagApplication(request.Context(), username, companyID, link.TagID)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) UntagApplication(response_writer http.ResponseWriter, request *http.Request) {
+	companyID := chi.URLParam(request, "companyID")
+	tagID, err := tagIDParam(request)
+	if err != nil {
+		http.Error(response_writer, "invalid tag ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	username := app.SessionManager.GetString(request.Context(), "username")
+	err = app.DB.UntagApplication(request.Context(), username, companyID, tagID)
+	if err != nil {
+		writeStoreError(response_writer, "DB update failed", err)
+		return
+	}
+
+	response_writer.WriteHeader(http.StatusNoContent)
+}
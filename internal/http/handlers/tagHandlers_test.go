@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTagsCRUD(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	status, body := sendRequest(t, app, router, token, http.MethodPost, "/tags", `{"name": "Dream job", "color": "#FF8800"}`)
+	if status != http.StatusCreated || !strings.Contains(body, `"color":"#ff8800"`) {
+		t.Fatalf("Unexpected create response %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/tags", `{"name": "dream JOB"}`)
+	if status != http.StatusConflict {
+		t.Errorf("Expected status code %d for a duplicate name, got %d", http.StatusConflict, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodPut, "/tags/1", `{"name": "Dream role"}`)
+	if status != http.StatusOK || !strings.Contains(body, `"color":"#808080"`) {
+		t.Errorf("Unexpected update response %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/tags", `{"tag_id": 1}`)
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company", "")
+	if status != http.StatusOK || !strings.Contains(body, `"tags":[{"id":1,"name":"Dream role"`) {
+		t.Errorf("Expected the tag on the application, got %d: %s", status, body)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/tags", `{"tag_id": 99}`)
+	if status != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing tag, got %d", http.StatusNotFound, status)
+	}
+
+	status, _ = sendRequest(t, app, router, token, http.MethodDelete, "/tags/1", "")
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNoContent, status)
+	}
+
+	status, body = sendRequest(t, app, router, token, http.MethodGet, "/applications/Fake%20Company/tags", "")
+	if status != http.StatusOK || strings.TrimSpace(body) != "[]" {
+		t.Errorf("Expected deleting the tag to untag the application, got %d: %s", status, body)
+	}
+}
+
+func TestListApplicationsByTag(t *testing.T) {
+	app, router, token, err := setupAll()
+	if err != nil {
+		t.Fatalf("Failed to setup session:%v", err.Error())
+	}
+
+	sendRequest(t, app, router, token, http.MethodPost, "/tags", `{"name": "Referral"}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/tags", `{"name": "Visa sponsor"}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/tags", `{"tag_id": 1}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Fake%20Company/tags", `{"tag_id": 2}`)
+	sendRequest(t, app, router, token, http.MethodPost, "/applications/Another%20Fake%20Company/tags", `{"tag_id": 2}`)
+
+	tests := []struct {
+		url      string
+		expected []string
+	}{
+		{"/applications?tag=referral", []string{"Fake Company"}},
+		{"/applications?tag=referral&tag=visa%20sponsor", []string{"Fake Company", "Another Fake Company"}},
+		{"/applications?tag=referral&tag=visa%20sponsor&tag_match=all", []string{"Fake Company"}},
+		{"/applications?tag=unused", []string{}},
+	}
+
+	for _, test := range tests {
+		status, body := sendRequest(t, app, router, token, http.MethodGet, test.url, "")
+		if status != http.StatusOK {
+			t.Errorf("%s: expected status code %d, got %d: %s", test.url, http.StatusOK, status, body)
+			continue
+		}
+		if count := strings.Count(body, `"company":`); count != len(test.expected) {
+			t.Errorf("%s: expected %d applications, got %s", test.url, len(test.expected), body)
+		}
+		for _, company := range test.expected {
+			if !strings.Contains(body, `"company":"`+company+`"`) {
+				t.Errorf("%s: expected %s in %s", test.url, company, body)
+			}
+		}
+	}
+
+	status, _ := sendRequest(t, app, router, token, http.MethodGet, "/applications?tag=referral&tag_match=some", "")
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid tag_match, got %d", http.StatusBadRequest, status)
+	}
+}
@@ -1,34 +1,75 @@
 package middleware
 
 import (
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/logging"
 )
 
-func ZapLoggerMiddleware(logger *zap.Logger) func(next http.Handler) http.Handler {
+// Writes an access log entry per request and stores a request-scoped logger, carrying the request ID and username, in the context.
+// Must run after RequestIDMiddleware and the session manager's LoadAndSave.
+func ZapLoggerMiddleware(logger *zap.Logger, session_manager *scs.SessionManager) func(next http.Handler) http.Handler {
 	return func(next_handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
 			wrapped_writer := middleware.NewWrapResponseWriter(response_writer, request.ProtoMajor)
+			start := time.Now()
+
+			request_id := RequestID(request.Context())
+
+			request_logger := logger.With(zap.String("request_id", request_id))
+			if span_context := trace.SpanContextFromContext(request.Context()); span_context.HasTraceID() {
+				request_logger = request_logger.With(zap.String("trace_id", span_context.TraceID().String()))
+			}
+			if username := session_manager.GetString(request.Context(), "username"); username != "" {
+				request_logger = request_logger.With(zap.String("username", username))
+			}
+
+			request = request.WithContext(logging.WithLogger(request.Context(), request_logger))
 
 			defer func() {
 				status := wrapped_writer.Status()
+				if status == 0 {
+					status = http.StatusOK // Handler never wrote, net/http sends 200.
+				}
+
+				// Read after the handler so logins and logouts are attributed correctly.
+				username := session_manager.GetString(request.Context(), "username")
+
+				fields := []zap.Field{
+					zap.String("request_id", request_id),
+					zap.String("method", request.Method),
+					zap.String("path", request.URL.Path),
+					zap.String("route", RoutePattern(request)),
+					zap.Int("status", status),
+					zap.Duration("duration", time.Since(start)),
+					zap.Int("bytes", wrapped_writer.BytesWritten()),
+					zap.String("remote_ip", remoteIP(request)),
+					zap.String("username", username),
+				}
 
 				if status >= 500 {
-					logger.Warn("Request failed",
-						zap.String("method", request.Method),
-						zap.String("path", request.URL.Path),
-						zap.Int("status", status))
+					logger.Warn("Request failed", fields...)
 				} else {
-					logger.Info("Request complete",
-						zap.String("method", request.Method),
-						zap.String("path", request.URL.Path),
-						zap.Int("status", status))
+					logger.Info("Request complete", fields...)
 				}
 			}()
-			
+
 			next_handler.ServeHTTP(wrapped_writer, request)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func remoteIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/medidew/ApplicationTracker/internal/metrics"
+)
+
+// Records request counts and latency, labelled by chi route pattern so path params don't blow up cardinality.
+func MetricsMiddleware(app_metrics *metrics.Metrics) func(next http.Handler) http.Handler {
+	return func(next_handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+			wrapped_writer := middleware.NewWrapResponseWriter(response_writer, request.ProtoMajor)
+			start := time.Now()
+
+			app_metrics.HTTPRequestsInFlight.Inc()
+			defer func() {
+				app_metrics.HTTPRequestsInFlight.Dec()
+
+				route := RoutePattern(request)
+				status := wrapped_writer.Status()
+				if status == 0 {
+					status = http.StatusOK // Handler never wrote, net/http sends 200.
+				}
+
+				app_metrics.HTTPRequests.WithLabelValues(request.Method, route, strconv.Itoa(status)).Inc()
+				app_metrics.HTTPRequestDuration.WithLabelValues(request.Method, route).Observe(time.Since(start).Seconds())
+			}()
+
+			next_handler.ServeHTTP(wrapped_writer, request)
+		})
+	}
+}
+
+// Returns the chi route pattern matched for `request`, or "unmatched". Only complete once routing has finished.
+func RoutePattern(request *http.Request) string {
+	route_context := chi.RouteContext(request.Context())
+	if route_context == nil {
+		return "unmatched"
+	}
+
+	pattern := route_context.RoutePattern()
+	if pattern == "" {
+		return "unmatched"
+	}
+
+	return pattern
+}
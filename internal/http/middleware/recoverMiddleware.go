@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/logging"
+	"github.com/medidew/ApplicationTracker/internal/metrics"
+)
+
+// RFC 9457 problem details, as returned for unrecovered errors.
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Recovers panics from later handlers, logging them with their stack and replying with a generic 500.
+// Nothing about the panic is sent to the client.
+func RecoverMiddleware(logger *zap.Logger, app_metrics *metrics.Metrics) func(next http.Handler) http.Handler {
+	return func(next_handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				// net/http uses this to deliberately abort a response, it isn't a bug.
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				app_metrics.Panics.WithLabelValues(RoutePattern(request)).Inc()
+
+				logging.FromContext(request.Context(), logger).Error("Handler panicked",
+					zap.String("request_id", RequestID(request.Context())),
+					zap.String("panic", fmt.Sprint(recovered)),
+					zap.ByteString("stack", debug.Stack()),
+				)
+
+				writeProblem(response_writer, request, http.StatusInternalServerError, "An unexpected error occurred.")
+			}()
+
+			next_handler.ServeHTTP(response_writer, request)
+		})
+	}
+}
+
+func writeProblem(response_writer http.ResponseWriter, request *http.Request, status int, detail string) {
+	response, err := json.Marshal(problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		RequestID: RequestID(request.Context()),
+	})
+	if err != nil {
+		http.Error(response_writer, http.StatusText(status), status)
+		return
+	}
+
+	// If the handler had already written headers this is a no-op, but the body still marks the failure.
+	response_writer.Header().Set("Content-Type", "application/problem+json")
+	response_writer.WriteHeader(status)
+	response_writer.Write(response)
+}
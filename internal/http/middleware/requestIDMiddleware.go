@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const RequestIDHeader string = "X-Request-ID"
+
+// Incoming IDs longer than this are replaced rather than trusted.
+const maxRequestIDLength int = 128
+
+type requestIDKey struct{}
+
+// Tags each request with an ID, reusing a well-formed incoming X-Request-ID so logs correlate across services.
+// The ID is echoed back in the X-Request-ID response header.
+func RequestIDMiddleware(next_handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+		request_id := request.Header.Get(RequestIDHeader)
+		if !validRequestID(request_id) {
+			request_id = newRequestID()
+		}
+
+		response_writer.Header().Set(RequestIDHeader, request_id)
+
+		ctx := context.WithValue(request.Context(), requestIDKey{}, request_id)
+		next_handler.ServeHTTP(response_writer, request.WithContext(ctx))
+	})
+}
+
+// Returns the ID set by RequestIDMiddleware, or "" outside of a request.
+func RequestID(ctx context.Context) string {
+	request_id, _ := ctx.Value(requestIDKey{}).(string)
+	return request_id
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Only allows characters that are safe to echo into headers and logs.
+func validRequestID(request_id string) bool {
+	if request_id == "" || len(request_id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range request_id {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		case char == '-', char == '_', char == '.', char == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/medidew/ApplicationTracker/internal/tracing"
+)
+
+// Starts a server span per request, continuing any incoming W3C trace context.
+// The span is renamed to the chi route pattern once routing has finished.
+func TracingMiddleware(provider trace.TracerProvider) func(next http.Handler) http.Handler {
+	tracer := provider.Tracer(tracing.TracerName)
+	propagator := propagation.TraceContext{}
+
+	return func(next_handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(response_writer http.ResponseWriter, request *http.Request) {
+			ctx := propagator.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+
+			ctx, span := tracer.Start(ctx, request.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", request.Method),
+					attribute.String("url.path", request.URL.Path),
+					attribute.String("request_id", RequestID(request.Context())),
+				),
+			)
+			defer span.End()
+
+			wrapped_writer := middleware.NewWrapResponseWriter(response_writer, request.ProtoMajor)
+			request = request.WithContext(ctx)
+
+			next_handler.ServeHTTP(wrapped_writer, request)
+
+			route := RoutePattern(request)
+			span.SetName(request.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+
+			status := wrapped_writer.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"context"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+type loggerKey struct{}
+
+// Builds the app logger: a rotated log file, plus stdout if `cfg.Console` is set.
+func New(cfg config.LoggingConfig) (*zap.Logger, error) {
+	file_level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	log_file_write_syncer := zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.File,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	})
+
+	cores := []zapcore.Core{
+		zapcore.NewCore(newEncoder(cfg.Encoding), log_file_write_syncer, file_level),
+	}
+
+	if cfg.Console {
+		console_level, err := zapcore.ParseLevel(cfg.ConsoleLevel)
+		if err != nil {
+			return nil, err
+		}
+
+		cores = append(cores, zapcore.NewCore(newEncoder(cfg.Encoding), zapcore.Lock(os.Stdout), console_level))
+	}
+
+	return zap.New(zapcore.NewTee(cores...)), nil
+}
+
+func newEncoder(encoding string) zapcore.Encoder {
+	if encoding == "json" {
+		encoder_cfg := zap.NewProductionEncoderConfig()
+		encoder_cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewJSONEncoder(encoder_cfg)
+	}
+
+	return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+}
+
+// Returns a copy of `ctx` carrying `logger`, for request-scoped fields like the request ID.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Returns the logger stored in `ctx` by WithLogger, or `fallback` if there isn't one.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+
+	return fallback
+}
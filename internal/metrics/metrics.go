@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+const namespace string = "apptracker"
+
+// How long a scrape waits on the database for domain gauges.
+const storeScrapeTimeout = 2 * time.Second
+
+// App-wide Prometheus metrics. Each Metrics has its own registry, so tests don't share global state.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	HTTPRequests         *prometheus.CounterVec   // Labelled by method, route pattern and status code.
+	HTTPRequestDuration  *prometheus.HistogramVec // Labelled by method and route pattern.
+	HTTPRequestsInFlight prometheus.Gauge
+
+	Panics *prometheus.CounterVec // Recovered handler panics, labelled by route pattern.
+
+	Logins *prometheus.CounterVec // Labelled by result, "success" or "failure".
+}
+
+func New() *Metrics {
+	metrics := &Metrics{
+		Registry: prometheus.NewRegistry(),
+
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by chi route pattern.",
+		}, []string{"method", "route", "status"}),
+
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by chi route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		HTTPRequestsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests currently being handled.",
+		}),
+
+		Panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "panics_total",
+			Help:      "Handler panics recovered, by chi route pattern.",
+		}, []string{"route"}),
+
+		Logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts, by result.",
+		}, []string{"result"}),
+	}
+
+	metrics.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.HTTPRequests,
+		metrics.HTTPRequestDuration,
+		metrics.HTTPRequestsInFlight,
+		metrics.Panics,
+		metrics.Logins,
+	)
+
+	return metrics
+}
+
+// Exposes pgxpool.Stat() as gauges and counters, read on each scrape.
+func (metrics *Metrics) RegisterPool(pool *pgxpool.Pool) error {
+	return metrics.Registry.Register(&poolCollector{pool: pool})
+}
+
+// Exposes the number of live sessions, if `session_store` supports iteration.
+func (metrics *Metrics) RegisterSessions(session_store scs.Store) error {
+	iterable_store, ok := session_store.(scs.IterableStore)
+	if !ok {
+		return nil
+	}
+
+	return metrics.Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sessions_active",
+		Help:      "Sessions currently held by the session store.",
+	}, func() float64 {
+		sessions, err := iterable_store.All()
+		if err != nil {
+			return 0
+		}
+		return float64(len(sessions))
+	}))
+}
+
+// Exposes domain gauges, such as applications per status, queried from `db` on each scrape.
+func (metrics *Metrics) RegisterStore(db store.Store) error {
+	return metrics.Registry.Register(&storeCollector{db: db})
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+var (
+	poolAcquiredConnsDesc    = prometheus.NewDesc(namespace+"_db_pool_acquired_conns", "Connections currently in use.", nil, nil)
+	poolIdleConnsDesc        = prometheus.NewDesc(namespace+"_db_pool_idle_conns", "Idle connections in the pool.", nil, nil)
+	poolConstructingDesc     = prometheus.NewDesc(namespace+"_db_pool_constructing_conns", "Connections currently being established.", nil, nil)
+	poolTotalConnsDesc       = prometheus.NewDesc(namespace+"_db_pool_total_conns", "Total connections in the pool.", nil, nil)
+	poolMaxConnsDesc         = prometheus.NewDesc(namespace+"_db_pool_max_conns", "Maximum size of the pool.", nil, nil)
+	poolAcquiresDesc         = prometheus.NewDesc(namespace+"_db_pool_acquires_total", "Successful connection acquires.", nil, nil)
+	poolAcquireDurationDesc  = prometheus.NewDesc(namespace+"_db_pool_acquire_duration_seconds_total", "Time spent acquiring connections.", nil, nil)
+	poolEmptyAcquiresDesc    = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", nil, nil)
+	poolCanceledAcquiresDesc = prometheus.NewDesc(namespace+"_db_pool_canceled_acquires_total", "Acquires canceled by their context.", nil, nil)
+	poolNewConnsDesc         = prometheus.NewDesc(namespace+"_db_pool_new_conns_total", "Connections opened.", nil, nil)
+)
+
+func (collector *poolCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- poolAcquiredConnsDesc
+	descs <- poolIdleConnsDesc
+	descs <- poolConstructingDesc
+	descs <- poolTotalConnsDesc
+	descs <- poolMaxConnsDesc
+	descs <- poolAcquiresDesc
+	descs <- poolAcquireDurationDesc
+	descs <- poolEmptyAcquiresDesc
+	descs <- poolCanceledAcquiresDesc
+	descs <- poolNewConnsDesc
+}
+
+func (collector *poolCollector) Collect(metrics chan<- prometheus.Metric) {
+	stat := collector.pool.Stat()
+
+	metrics <- prometheus.MustNewConstMetric(poolAcquiredConnsDesc, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	metrics <- prometheus.MustNewConstMetric(poolIdleConnsDesc, prometheus.GaugeValue, float64(stat.IdleConns()))
+	metrics <- prometheus.MustNewConstMetric(poolConstructingDesc, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	metrics <- prometheus.MustNewConstMetric(poolTotalConnsDesc, prometheus.GaugeValue, float64(stat.TotalConns()))
+	metrics <- prometheus.MustNewConstMetric(poolMaxConnsDesc, prometheus.GaugeValue, float64(stat.MaxConns()))
+	metrics <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(stat.AcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	metrics <- prometheus.MustNewConstMetric(poolEmptyAcquiresDesc, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(poolCanceledAcquiresDesc, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	metrics <- prometheus.MustNewConstMetric(poolNewConnsDesc, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
+
+type storeCollector struct {
+	db store.Store
+}
+
+var (
+	applicationsDesc      = prometheus.NewDesc(namespace+"_applications", "Applications across all users, by status.", []string{"status"}, nil)
+	storeScrapeErrorsDesc = prometheus.NewDesc(namespace+"_store_scrape_error", "1 if the last scrape of domain gauges from the store failed.", nil, nil)
+)
+
+func (collector *storeCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- applicationsDesc
+	descs <- storeScrapeErrorsDesc
+}
+
+func (collector *storeCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeScrapeTimeout)
+	defer cancel()
+
+	counts, err := collector.db.CountApplicationsByStatus(ctx)
+	if err != nil {
+		metrics <- prometheus.MustNewConstMetric(storeScrapeErrorsDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(storeScrapeErrorsDesc, prometheus.GaugeValue, 0)
+
+	// Report every status, even at zero, so series don't disappear.
+	for status := store.Active; status <= store.MaxStatus; status++ {
+		metrics <- prometheus.MustNewConstMetric(applicationsDesc, prometheus.GaugeValue, float64(counts[status]), status.String())
+	}
+}
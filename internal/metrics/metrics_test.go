@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+func TestStoreCollectorCountsApplicationsPerStatus(t *testing.T) {
+	application_one, err := store.NewJobApplication("Company One", store.SoftwareEngineer, store.Active, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+	application_two, err := store.NewJobApplication("Company Two", store.SoftwareEngineer, store.Offer, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+
+	fake_store := store.NewFakeStore(map[string][]*store.JobApplication{
+		"testuser": {application_one, application_two},
+	})
+
+	metrics := New()
+	err = metrics.RegisterStore(fake_store)
+	if err != nil {
+		t.Fatalf("RegisterStore() error: %v", err)
+	}
+
+	expected := `
+# HELP apptracker_applications Applications across all users, by status.
+# TYPE apptracker_applications gauge
+apptracker_applications{status="Active"} 1
+apptracker_applications{status="Offer"} 1
+apptracker_applications{status="Pending Response"} 0
+apptracker_applications{status="Rejected"} 0
+`
+	err = testutil.GatherAndCompare(metrics.Registry, strings.NewReader(expected), "apptracker_applications")
+	if err != nil {
+		t.Fatalf("Unexpected metrics: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+// Package posting extracts job details from the saved HTML of a job advert, using schema.org
+// JobPosting JSON-LD where the page has it and OpenGraph tags otherwise.
+package posting
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Details found in a job advert. Fields the page didn't have are left empty.
+type Posting struct {
+	Title    string       `json:"title,omitempty"`
+	Company  string       `json:"company,omitempty"`
+	Location string       `json:"location,omitempty"`
+	Remote   bool         `json:"remote,omitempty"` // JSON-LD jobLocationType TELECOMMUTE.
+	Salary   *SalaryRange `json:"salary,omitempty"`
+}
+
+type SalaryRange struct {
+	Currency string  `json:"currency,omitempty"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Period   string  `json:"period,omitempty"` // Lower case schema.org unitText, e.g. "year" or "hour".
+}
+
+// Reports whether nothing was found.
+func (posting *Posting) IsEmpty() bool {
+	return posting.Title == "" && posting.Company == "" && posting.Location == "" && !posting.Remote && posting.Salary == nil
+}
+
+// The parts of the page Parse looks at.
+type document struct {
+	jsonLD []string
+	meta   map[string]string // OpenGraph property or meta name, lower case, to content.
+	title  string
+}
+
+// Reads the page and extracts what it can. Only read errors are returned; a page without
+// any job metadata gives an empty Posting, and malformed JSON-LD blocks are skipped.
+func Parse(reader io.Reader) (*Posting, error) {
+	doc, err := scan(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	posting := &Posting{}
+
+	for _, block := range doc.jsonLD {
+		var node any
+		if json.Unmarshal([]byte(block), &node) != nil {
+			continue
+		}
+
+		job_posting := findJobPosting(node)
+		if job_posting != nil {
+			fromJobPosting(posting, job_posting)
+			break
+		}
+	}
+
+	if posting.Title == "" {
+		posting.Title = doc.meta["og:title"]
+	}
+	if posting.Title == "" {
+		posting.Title = doc.title
+	}
+	if posting.Company == "" {
+		posting.Company = doc.meta["og:site_name"]
+	}
+	if posting.Location == "" {
+		posting.Location = joinNonEmpty(", ", doc.meta["og:locality"], doc.meta["og:region"], doc.meta["og:country-name"])
+	}
+
+	return posting, nil
+}
+
+func scan(reader io.Reader) (*document, error) {
+	doc := &document{meta: map[string]string{}}
+	tokenizer := nethtml.NewTokenizer(reader)
+
+	for {
+		token_type := tokenizer.Next()
+
+		switch token_type {
+		case nethtml.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				return doc, nil
+			}
+			return nil, tokenizer.Err()
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			switch token.DataAtom {
+			case atom.Meta:
+				key := strings.ToLower(attribute(token, "property"))
+				if key == "" {
+					key = strings.ToLower(attribute(token, "name"))
+				}
+				if _, seen := doc.meta[key]; key != "" && !seen {
+					doc.meta[key] = strings.TrimSpace(attribute(token, "content"))
+				}
+			case atom.Script:
+				if strings.EqualFold(strings.TrimSpace(attribute(token, "type")), "application/ld+json") && tokenizer.Next() == nethtml.TextToken {
+					doc.jsonLD = append(doc.jsonLD, string(tokenizer.Text()))
+				}
+			case atom.Title:
+				if doc.title == "" && tokenizer.Next() == nethtml.TextToken {
+					doc.title = strings.TrimSpace(string(tokenizer.Text()))
+				}
+			}
+		}
+	}
+}
+
+func attribute(token nethtml.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// Finds the first JobPosting in a JSON-LD value, looking through arrays and @graph.
+func findJobPosting(node any) map[string]any {
+	switch value := node.(type) {
+	case []any:
+		for _, item := range value {
+			found := findJobPosting(item)
+			if found != nil {
+				return found
+			}
+		}
+	case map[string]any:
+		if hasType(value, "JobPosting") {
+			return value
+		}
+		return findJobPosting(value["@graph"])
+	}
+
+	return nil
+}
+
+// @type may be a single string or a list, and is sometimes a full schema.org URL.
+func hasType(object map[string]any, name string) bool {
+	types, ok := object["@type"].([]any)
+	if !ok {
+		types = []any{object["@type"]}
+	}
+
+	for _, item := range types {
+		type_name, _ := item.(string)
+		if type_name == name || strings.HasSuffix(type_name, "/"+name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fromJobPosting(posting *Posting, job_posting map[string]any) {
+	posting.Title = text(job_posting["title"])
+	posting.Company = text(job_posting["hiringOrganization"])
+	posting.Remote = strings.EqualFold(text(job_posting["jobLocationType"]), "TELECOMMUTE")
+
+	locations := []string{}
+	for _, place := range list(job_posting["jobLocation"]) {
+		location := placeName(place)
+		if location != "" {
+			locations = append(locations, location)
+		}
+	}
+	posting.Location = strings.Join(locations, "; ")
+
+	posting.Salary = salary(job_posting["baseSalary"])
+}
+
+// Formats a Place as "locality, region, country", or uses its name or address text as is.
+func placeName(place any) string {
+	object, ok := place.(map[string]any)
+	if !ok {
+		return text(place)
+	}
+
+	address, ok := object["address"].(map[string]any)
+	if !ok {
+		return joinNonEmpty(", ", text(object["address"]), text(object["name"]))
+	}
+
+	location := joinNonEmpty(", ", text(address["addressLocality"]), text(address["addressRegion"]), text(address["addressCountry"]))
+	if location == "" {
+		location = text(address["name"])
+	}
+
+	return location
+}
+
+// Reads a MonetaryAmount, whose value is a number or a QuantitativeValue with a min and max.
+func salary(node any) *SalaryRange {
+	object, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	salary_range := &SalaryRange{Currency: strings.ToUpper(text(object["currency"]))}
+
+	value := object["value"]
+	if quantity, ok := value.(map[string]any); ok {
+		salary_range.Period = strings.ToLower(text(quantity["unitText"]))
+
+		exact, has_exact := number(quantity["value"])
+		salary_range.Min, ok = number(quantity["minValue"])
+		if !ok {
+			salary_range.Min = exact
+		}
+		salary_range.Max, ok = number(quantity["maxValue"])
+		if !ok {
+			salary_range.Max = exact
+		}
+		if !has_exact && salary_range.Min == 0 && salary_range.Max == 0 {
+			return nil
+		}
+	} else {
+		exact, ok := number(value)
+		if !ok {
+			return nil
+		}
+		salary_range.Min = exact
+		salary_range.Max = exact
+	}
+
+	if salary_range.Max < salary_range.Min {
+		salary_range.Max = salary_range.Min
+	}
+
+	return salary_range
+}
+
+// Reads a JSON-LD value as text: strings as they are, objects by their name, lists by their first entry.
+func text(node any) string {
+	switch value := node.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(value))
+	case map[string]any:
+		return text(value["name"])
+	case []any:
+		for _, item := range value {
+			item_text := text(item)
+			if item_text != "" {
+				return item_text
+			}
+		}
+	}
+
+	return ""
+}
+
+// Numbers are often written as strings, sometimes with thousands separators.
+func number(node any) (float64, bool) {
+	switch value := node.(type) {
+	case float64:
+		return value, true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+		return parsed, err == nil
+	}
+
+	return 0, false
+}
+
+func list(node any) []any {
+	if items, ok := node.([]any); ok {
+		return items
+	}
+	if node == nil {
+		return nil
+	}
+	return []any{node}
+}
+
+func joinNonEmpty(separator string, values ...string) string {
+	parts := []string{}
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, separator)
+}
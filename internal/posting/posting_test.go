@@ -0,0 +1,66 @@
+package posting
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		expected Posting
+	}{
+		{"jsonld.html", Posting{
+			Title:    "Senior Backend Engineer",
+			Company:  "Acme & Sons",
+			Location: "London, England, GB",
+			Salary:   &SalaryRange{Currency: "GBP", Min: 70000, Max: 90000, Period: "year"},
+		}},
+		{"jsonld_graph.html", Posting{
+			Title:    "Platform Engineer",
+			Company:  "Globex Corporation",
+			Location: "Berlin, Germany; Amsterdam, Netherlands",
+			Remote:   true,
+			Salary:   &SalaryRange{Currency: "EUR", Min: 85000, Max: 85000, Period: "year"},
+		}},
+		{"opengraph.html", Posting{
+			Title:    "Data Engineer at Initech",
+			Company:  "Initech",
+			Location: "Austin, TX, USA",
+		}},
+		{"none.html", Posting{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", test.fixture))
+			if err != nil {
+				t.Fatalf("Failed to open fixture: %v", err)
+			}
+			defer file.Close()
+
+			posting, err := Parse(file)
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			if !reflect.DeepEqual(*posting, test.expected) {
+				t.Errorf("Expected %+v (salary %+v), got %+v (salary %+v)", test.expected, test.expected.Salary, *posting, posting.Salary)
+			}
+		})
+	}
+}
+
+func TestParseFallsBackToTitle(t *testing.T) {
+	posting, err := Parse(strings.NewReader(`<html><head><title>Engineer &amp; Analyst</title></head></html>`))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if posting.Title != "Engineer & Analyst" || posting.Company != "" || posting.IsEmpty() {
+		t.Errorf("Unexpected posting %+v", posting)
+	}
+}
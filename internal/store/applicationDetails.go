@@ -0,0 +1,117 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Type for standardising where the work is done.
+type RemotePolicy string
+
+const (
+	Onsite RemotePolicy = "onsite"
+	Hybrid RemotePolicy = "hybrid"
+	Remote RemotePolicy = "remote"
+)
+
+func GetSupportedRemotePolicies() []RemotePolicy {
+	return []RemotePolicy{Onsite, Hybrid, Remote}
+}
+
+// Type for standardising the kind of contract on offer.
+type EmploymentType string
+
+const (
+	FullTime   EmploymentType = "full_time"
+	PartTime   EmploymentType = "part_time"
+	Contract   EmploymentType = "contract"
+	Internship EmploymentType = "internship"
+	Temporary  EmploymentType = "temporary"
+)
+
+func GetSupportedEmploymentTypes() []EmploymentType {
+	return []EmploymentType{FullTime, PartTime, Contract, Internship, Temporary}
+}
+
+// Type for standardising how the applicant found the job.
+type ApplicationSource string
+
+const (
+	Referral          ApplicationSource = "referral"
+	JobBoard          ApplicationSource = "job_board"
+	RecruiterOutreach ApplicationSource = "recruiter_outreach"
+	CompanyWebsite    ApplicationSource = "company_website"
+	OtherSource       ApplicationSource = "other"
+)
+
+func GetSupportedApplicationSources() []ApplicationSource {
+	return []ApplicationSource{Referral, JobBoard, RecruiterOutreach, CompanyWebsite, OtherSource}
+}
+
+// Advertised pay for a job. Amounts are in Currency, per Period.
+type SalaryRange struct {
+	Min      float64   `json:"min"`
+	Max      float64   `json:"max"`
+	Currency string    `json:"currency"` // ISO 4217 code, e.g. "GBP".
+	Period   PayPeriod `json:"period"`
+}
+
+// Checks the range, filling in defaults.
+func (salary *SalaryRange) Validate() error {
+	salary.Currency = strings.ToUpper(strings.TrimSpace(salary.Currency))
+	if !currencyCodePattern.MatchString(salary.Currency) {
+		return errors.New("`salary.currency` must be a three letter ISO 4217 code")
+	}
+
+	if salary.Min < 0 || salary.Max < salary.Min {
+		return errors.New("`salary` must satisfy 0 <= min <= max")
+	}
+
+	if salary.Period == "" {
+		salary.Period = PerYear
+	}
+	if !slices.Contains(GetSupportedPayPeriods(), salary.Period) {
+		return errors.New("`salary.period` is not supported by type PayPeriod")
+	}
+
+	return nil
+}
+
+// The top of the range as a yearly amount.
+func (salary *SalaryRange) annualMax() float64 {
+	return salary.Max * periodsPerYear[salary.Period]
+}
+
+// What's known about the job beyond its company and role. Empty fields are unknown.
+type ApplicationDetails struct {
+	Salary         *SalaryRange      `json:"salary"`
+	Location       string            `json:"location"`
+	RemotePolicy   RemotePolicy      `json:"remote_policy"`
+	EmploymentType EmploymentType    `json:"employment_type"`
+	Source         ApplicationSource `json:"source"`
+}
+
+// Checks the details, filling in defaults.
+func (details *ApplicationDetails) Validate() error {
+	if details.Salary != nil {
+		err := details.Salary.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	details.Location = strings.TrimSpace(details.Location)
+
+	if details.RemotePolicy != "" && !slices.Contains(GetSupportedRemotePolicies(), details.RemotePolicy) {
+		return errors.New("`remote_policy` is not supported by type RemotePolicy")
+	}
+	if details.EmploymentType != "" && !slices.Contains(GetSupportedEmploymentTypes(), details.EmploymentType) {
+		return errors.New("`employment_type` is not supported by type EmploymentType")
+	}
+	if details.Source != "" && !slices.Contains(GetSupportedApplicationSources(), details.Source) {
+		return errors.New("`source` is not supported by type ApplicationSource")
+	}
+
+	return nil
+}
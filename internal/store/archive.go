@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Archives applications that have had Status for longer than After, such as rejections after 30 days.
+// Unarchiving an application restarts the clock.
+type ArchiveRule struct {
+	Status ApplicationStatus
+	After  time.Duration
+}
+
+func (rule ArchiveRule) Validate() error {
+	if rule.Status > MaxStatus {
+		return errors.New("`status` is not supported by type ApplicationStatus")
+	}
+	if rule.After <= 0 {
+		return errors.New("`after` must be positive")
+	}
+
+	return nil
+}
+
+// Reports whether the rule archives `job_application` at `now`. Used by FakeStore, DB checks in SQL.
+func (rule ArchiveRule) Matches(job_application *JobApplication, now time.Time) bool {
+	if job_application.status != rule.Status || !job_application.archivedAt.IsZero() {
+		return false
+	}
+
+	since := job_application.statusChangedAt
+	if job_application.unarchivedAt.After(since) {
+		since = job_application.unarchivedAt
+	}
+
+	return since.Before(now.Add(-rule.After))
+}
+
+// Hides the application from the pipeline without changing its status. Archiving twice is a no-op.
+func (db *DB) ArchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set archived_at=coalesce(archived_at, now()), version=version+1
+		where company=$1 and username=$2 and deleted_at is null and ($3::bigint = 0 or version=$3)`, companyID, username, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Returns the application to the pipeline. Unarchiving a live application is a no-op.
+func (db *DB) UnarchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set
+			unarchived_at = case when archived_at is null then unarchived_at else now() end,
+			archived_at = null,
+			version = version + 1
+		where company=$1 and username=$2 and deleted_at is null and ($3::bigint = 0 or version=$3)`, companyID, username, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Applies `rules` to every user's live applications, returning how many were archived.
+func (db *DB) ArchiveStaleApplications(ctx context.Context, rules []ArchiveRule, now time.Time) (int64, error) {
+	archived := int64(0)
+
+	for _, rule := range rules {
+		err := rule.Validate()
+		if err != nil {
+			return archived, err
+		}
+
+		// greatest() ignores nulls, so never-unarchived applications count from their status change.
+		command_tag, err := db.conn().Exec(ctx, `update applications set archived_at=$1, version=version+1
+			where archived_at is null and deleted_at is null and status=$2 and greatest(status_changed_at, unarchived_at) < $3`,
+			now,
+			rule.Status,
+			now.Add(-rule.After),
+		)
+		if err != nil {
+			return archived, err
+		}
+		archived += command_tag.RowsAffected()
+	}
+
+	return archived, nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveRuleMatches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rule := ArchiveRule{Status: Rejected, After: 30 * 24 * time.Hour}
+
+	newApplication := func(status ApplicationStatus, status_changed_at time.Time) *JobApplication {
+		job_application, err := NewJobApplication("Medidew Inc.", SoftwareEngineer, status, []string{})
+		if err != nil {
+			t.Fatalf("Failed to create NewJobApplication: %v", err)
+		}
+		job_application.SetStatusChangedAt(status_changed_at)
+		return job_application
+	}
+
+	stale := newApplication(Rejected, now.AddDate(0, 0, -31))
+	if !rule.Matches(stale, now) {
+		t.Errorf("Expected a rejection from 31 days ago to be archived")
+	}
+
+	if rule.Matches(newApplication(Rejected, now.AddDate(0, 0, -29)), now) {
+		t.Errorf("Expected a rejection from 29 days ago to be kept")
+	}
+	if rule.Matches(newApplication(Active, now.AddDate(0, 0, -60)), now) {
+		t.Errorf("Expected other statuses to be kept")
+	}
+
+	stale.unarchivedAt = now.AddDate(0, 0, -1)
+	if rule.Matches(stale, now) {
+		t.Errorf("Expected unarchiving to restart the clock")
+	}
+
+	stale.unarchivedAt = time.Time{}
+	stale.SetArchivedAt(now.AddDate(0, 0, -1))
+	if rule.Matches(stale, now) {
+		t.Errorf("Expected an archived application not to match again")
+	}
+}
+
+func TestArchiveRuleValidate(t *testing.T) {
+	if (ArchiveRule{Status: Rejected, After: time.Hour}).Validate() != nil {
+		t.Errorf("Expected a valid rule")
+	}
+	if (ArchiveRule{Status: Rejected}).Validate() == nil {
+		t.Errorf("Expected an error for a rule without a period")
+	}
+	if (ArchiveRule{Status: MaxStatus + 1, After: time.Hour}).Validate() == nil {
+		t.Errorf("Expected an error for an unknown status")
+	}
+}
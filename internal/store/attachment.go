@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Type for standardising what an attachment is for.
+type AttachmentKind string
+
+const (
+	Resume          AttachmentKind = "resume"
+	CoverLetter     AttachmentKind = "cover_letter"
+	OtherAttachment AttachmentKind = "other"
+)
+
+func GetSupportedAttachmentKinds() []AttachmentKind {
+	return []AttachmentKind{Resume, CoverLetter, OtherAttachment}
+}
+
+func (kind AttachmentKind) Validate() error {
+	if !slices.Contains(GetSupportedAttachmentKinds(), kind) {
+		return errors.New("`kind` is not supported by type AttachmentKind")
+	}
+	return nil
+}
+
+// Metadata for a file attached to an application. The contents live in blob storage under StorageKey.
+type Attachment struct {
+	ID          int64          `json:"id"`
+	Company     string         `json:"company"`
+	Kind        AttachmentKind `json:"kind"`
+	Filename    string         `json:"filename"`
+	ContentType string         `json:"content_type"` // Sniffed from the contents, not taken from the upload.
+	SizeBytes   int64          `json:"size_bytes"`
+	SHA256      string         `json:"sha256"` // Hex encoded.
+	StorageKey  string         `json:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+const attachmentColumns string = "id, company, kind, filename, content_type, size_bytes, sha256, storage_key, created_at"
+
+func scanAttachment(row pgx.Row) (*Attachment, error) {
+	attachment := &Attachment{}
+
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.Company,
+		&attachment.Kind,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.SHA256,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Lists the application's attachments. Those of a trashed application are hidden along with it.
+func (db *DB) ListAttachments(ctx context.Context, username string, companyID string) ([]*Attachment, error) {
+	rows, err := db.conn().Query(ctx, "select "+attachmentColumns+" from attachments where username=$1 and company=$2 and "+notTrashed("attachments")+" order by created_at, id", username, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*Attachment{}
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (db *DB) GetAttachment(ctx context.Context, username string, companyID string, attachmentID int64) (*Attachment, error) {
+	return scanAttachment(db.conn().QueryRow(ctx, "select "+attachmentColumns+" from attachments where id=$1 and username=$2 and company=$3 and "+notTrashed("attachments"), attachmentID, username, companyID))
+}
+
+// Records an uploaded attachment, setting its ID and CreatedAt.
+func (db *DB) CreateAttachment(ctx context.Context, username string, attachment *Attachment) error {
+	// Selected through the live application, so a trashed one can't gain attachments.
+	err := db.conn().QueryRow(ctx, `insert into attachments (username, company, kind, filename, content_type, size_bytes, sha256, storage_key)
+		select username, company, $3, $4, $5, $6, $7, $8 from applications where username=$1 and company=$2 and deleted_at is null
+		returning id, created_at`,
+		username,
+		attachment.Company,
+		attachment.Kind,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.SizeBytes,
+		attachment.SHA256,
+		attachment.StorageKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// Deletes the attachment's metadata. The caller removes the blob.
+func (db *DB) DeleteAttachment(ctx context.Context, username string, companyID string, attachmentID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from attachments where id=$1 and username=$2 and company=$3 and "+notTrashed("attachments"), attachmentID, username, companyID)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Type for standardising how a contact relates to an application.
+type ContactRole string
+
+const (
+	Recruiter     ContactRole = "recruiter"
+	HiringManager ContactRole = "hiring_manager"
+	Interviewer   ContactRole = "interviewer"
+	Referrer      ContactRole = "referrer"
+	OtherContact  ContactRole = "other"
+)
+
+func GetSupportedContactRoles() []ContactRole {
+	return []ContactRole{Recruiter, HiringManager, Interviewer, Referrer, OtherContact}
+}
+
+// Someone the user has dealt with, such as a recruiter. Linked to any number of applications.
+type Contact struct {
+	ID          int64       `json:"id"`
+	Name        string      `json:"name"`
+	Email       string      `json:"email"`
+	Phone       string      `json:"phone"`
+	LinkedInURL string      `json:"linkedin_url"`
+	Company     string      `json:"company"`
+	Role        ContactRole `json:"role"`
+}
+
+// Checks required fields and formats, defaulting an empty role to OtherContact.
+func (contact *Contact) Validate() error {
+	contact.Name = strings.TrimSpace(contact.Name)
+	if contact.Name == "" {
+		return errors.New("`name` is required")
+	}
+
+	if contact.Role == "" {
+		contact.Role = OtherContact
+	}
+	if !slices.Contains(GetSupportedContactRoles(), contact.Role) {
+		return errors.New("`role` is not supported by type ContactRole")
+	}
+
+	if contact.Email != "" {
+		address, err := mail.ParseAddress(contact.Email)
+		if err != nil || address.Address != contact.Email {
+			return errors.New("`email` is not a valid address")
+		}
+	}
+
+	if contact.LinkedInURL != "" {
+		parsed_url, err := url.Parse(contact.LinkedInURL)
+		if err != nil || (parsed_url.Scheme != "https" && parsed_url.Scheme != "http") || parsed_url.Host == "" {
+			return errors.New("`linkedin_url` must be an http or https URL")
+		}
+	}
+
+	return nil
+}
+
+const contactColumns string = "id, name, email, phone, linkedin_url, company, role"
+
+func scanContact(row pgx.Row) (*Contact, error) {
+	contact := &Contact{}
+
+	err := row.Scan(&contact.ID, &contact.Name, &contact.Email, &contact.Phone, &contact.LinkedInURL, &contact.Company, &contact.Role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (db *DB) queryContacts(ctx context.Context, sql string, args ...any) ([]*Contact, error) {
+	rows, err := db.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []*Contact{}
+
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+func (db *DB) ListContacts(ctx context.Context, username string) ([]*Contact, error) {
+	return db.queryContacts(ctx, "select "+contactColumns+" from contacts where username=$1 order by name, id", username)
+}
+
+func (db *DB) GetContact(ctx context.Context, username string, contactID int64) (*Contact, error) {
+	return scanContact(db.conn().QueryRow(ctx, "select "+contactColumns+" from contacts where id=$1 and username=$2", contactID, username))
+}
+
+// Saves a new contact, setting its ID.
+func (db *DB) CreateContact(ctx context.Context, username string, contact *Contact) error {
+	return db.conn().QueryRow(ctx, "insert into contacts (username, name, email, phone, linkedin_url, company, role) values ($1, $2, $3, $4, $5, $6, $7) returning id",
+		username,
+		contact.Name,
+		contact.Email,
+		contact.Phone,
+		contact.LinkedInURL,
+		contact.Company,
+		contact.Role,
+	).Scan(&contact.ID)
+}
+
+// Replaces every field of the contact with ID contact.ID.
+func (db *DB) UpdateContact(ctx context.Context, username string, contact *Contact) error {
+	command_tag, err := db.conn().Exec(ctx, "update contacts set name=$1, email=$2, phone=$3, linkedin_url=$4, company=$5, role=$6 where id=$7 and username=$8",
+		contact.Name,
+		contact.Email,
+		contact.Phone,
+		contact.LinkedInURL,
+		contact.Company,
+		contact.Role,
+		contact.ID,
+		username,
+	)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Deletes the contact and unlinks it from every application.
+func (db *DB) DeleteContact(ctx context.Context, username string, contactID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from contacts where id=$1 and username=$2", contactID, username)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) ListApplicationContacts(ctx context.Context, username string, companyID string) ([]*Contact, error) {
+	return db.queryContacts(ctx, "select "+prefixColumns("contacts", contactColumns)+` from contacts
+		join application_contacts on application_contacts.contact_id = contacts.id
+		where application_contacts.username=$1 and application_contacts.company=$2
+		order by contacts.name, contacts.id`,
+		username,
+		companyID,
+	)
+}
+
+// Links the contact to the application. Linking twice is a no-op.
+func (db *DB) LinkContact(ctx context.Context, username string, companyID string, contactID int64) error {
+	// Both must belong to the user, so select through them rather than inserting the IDs as given.
+	_, err := db.conn().Exec(ctx, `insert into application_contacts (username, company, contact_id)
+		select applications.username, applications.company, contacts.id
+		from applications join contacts on contacts.username = applications.username
+		where applications.username=$1 and applications.company=$2 and applications.deleted_at is null and contacts.id=$3
+		on conflict do nothing`,
+		username,
+		companyID,
+		contactID,
+	)
+	if err != nil {
+		return err
+	}
+
+	var linked bool
+	err = db.conn().QueryRow(ctx, "select exists (select 1 from application_contacts where username=$1 and company=$2 and contact_id=$3)", username, companyID, contactID).Scan(&linked)
+	if err != nil {
+		return err
+	}
+	if !linked {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) UnlinkContact(ctx context.Context, username string, companyID string, contactID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from application_contacts where username=$1 and company=$2 and contact_id=$3", username, companyID, contactID)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Qualifies each of a comma-separated column list with `table`, for use in joins.
+func prefixColumns(table string, columns string) string {
+	qualified := strings.Split(columns, ", ")
+	for i, column := range qualified {
+		qualified[i] = table + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
@@ -0,0 +1,34 @@
+package store
+
+import "testing"
+
+func TestContactValidate(t *testing.T) {
+	contact := &Contact{Name: "  Jane Doe ", Email: "jane@example.com", LinkedInURL: "https://www.linkedin.com/in/janedoe"}
+
+	err := contact.Validate()
+	if err != nil {
+		t.Fatalf("Expected a valid contact, got %v", err)
+	}
+	if contact.Name != "Jane Doe" {
+		t.Errorf("Expected the name to be trimmed, got %q", contact.Name)
+	}
+	if contact.Role != OtherContact {
+		t.Errorf("Expected the role to default to %q, got %q", OtherContact, contact.Role)
+	}
+}
+
+func TestContactValidateInvalid(t *testing.T) {
+	cases := map[string]Contact{
+		"no name":       {Email: "jane@example.com"},
+		"bad email":     {Name: "Jane", Email: "jane at example"},
+		"display email": {Name: "Jane", Email: "Jane <jane@example.com>"},
+		"bad linkedin":  {Name: "Jane", LinkedInURL: "linkedin.com/in/jane"},
+		"bad role":      {Name: "Jane", Role: "landlord"},
+	}
+
+	for name, contact := range cases {
+		if contact.Validate() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
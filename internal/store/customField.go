@@ -0,0 +1,324 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Type for standardising what a custom field holds.
+type CustomFieldType string
+
+const (
+	TextField   CustomFieldType = "text"
+	NumberField CustomFieldType = "number"
+	DateField   CustomFieldType = "date" // Stored as YYYY-MM-DD.
+	BoolField   CustomFieldType = "bool"
+	EnumField   CustomFieldType = "enum" // One of Options.
+)
+
+func GetSupportedCustomFieldTypes() []CustomFieldType {
+	return []CustomFieldType{TextField, NumberField, DateField, BoolField, EnumField}
+}
+
+// Returned by UpdateCustomField when the type would change.
+var ErrCustomFieldType = errors.New("a custom field's type can't be changed")
+
+const (
+	maxCustomFieldNameLength int = 50
+	maxCustomFieldTextLength int = 1000
+)
+
+// A field the user has added to their applications, such as "team size". Names are unique per user,
+// ignoring case, and are the keys of each application's custom field values.
+type CustomField struct {
+	ID      int64           `json:"id"`
+	Name    string          `json:"name"`
+	Type    CustomFieldType `json:"type"`
+	Options []string        `json:"options,omitempty"` // The allowed values of an enum field.
+}
+
+// Checks the name, type and options.
+func (field *CustomField) Validate() error {
+	field.Name = strings.TrimSpace(field.Name)
+	if field.Name == "" {
+		return errors.New("`name` is required")
+	}
+	if utf8.RuneCountInString(field.Name) > maxCustomFieldNameLength {
+		return errors.New("`name` must be at most 50 characters")
+	}
+
+	if !slices.Contains(GetSupportedCustomFieldTypes(), field.Type) {
+		return errors.New("`type` is not supported by type CustomFieldType")
+	}
+
+	if field.Type != EnumField {
+		if len(field.Options) > 0 {
+			return errors.New("`options` are only allowed on enum fields")
+		}
+		field.Options = nil
+		return nil
+	}
+
+	options := []string{}
+	for _, option := range field.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return errors.New("`options` must not be empty strings")
+		}
+		if slices.Contains(options, option) {
+			return fmt.Errorf("`options` contains %q twice", option)
+		}
+		options = append(options, option)
+	}
+	if len(options) == 0 {
+		return errors.New("enum fields need at least one option")
+	}
+	field.Options = options
+
+	return nil
+}
+
+// Checks `value` has the field's type, returning it normalised: dates as YYYY-MM-DD and trimmed strings.
+// `value` is as decoded from JSON, so numbers are float64.
+func (field *CustomField) normaliseValue(value any) (any, error) {
+	switch field.Type {
+	case TextField:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("custom field %q must be text", field.Name)
+		}
+		if utf8.RuneCountInString(text) > maxCustomFieldTextLength {
+			return nil, fmt.Errorf("custom field %q must be at most 1000 characters", field.Name)
+		}
+		return text, nil
+	case NumberField:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("custom field %q must be a number", field.Name)
+		}
+		return number, nil
+	case DateField:
+		text, ok := value.(string)
+		if ok {
+			date, err := time.Parse(time.DateOnly, strings.TrimSpace(text))
+			if err == nil {
+				return date.Format(time.DateOnly), nil
+			}
+		}
+		return nil, fmt.Errorf("custom field %q must be a date formatted YYYY-MM-DD", field.Name)
+	case BoolField:
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("custom field %q must be true or false", field.Name)
+		}
+		return boolean, nil
+	case EnumField:
+		text, ok := value.(string)
+		if !ok || !slices.Contains(field.Options, strings.TrimSpace(text)) {
+			return nil, fmt.Errorf("custom field %q must be one of %s", field.Name, strings.Join(field.Options, ", "))
+		}
+		return strings.TrimSpace(text), nil
+	default:
+		return nil, fmt.Errorf("custom field %q has unsupported type %q", field.Name, field.Type)
+	}
+}
+
+// Checks `values` against the user's custom fields, returning a normalised copy. Keys must name a field,
+// matching its case; null values are dropped, so sending null clears a field.
+func ValidateCustomFieldValues(schema []*CustomField, values map[string]any) (map[string]any, error) {
+	normalised := map[string]any{}
+
+	for name, value := range values {
+		i := slices.IndexFunc(schema, func(field *CustomField) bool { return field.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown custom field %q", name)
+		}
+		if value == nil {
+			continue
+		}
+
+		value, err := schema[i].normaliseValue(value)
+		if err != nil {
+			return nil, err
+		}
+		normalised[name] = value
+	}
+
+	return normalised, nil
+}
+
+const customFieldColumns string = "id, name, type, options"
+
+func scanCustomField(row pgx.Row) (*CustomField, error) {
+	field := &CustomField{}
+
+	err := row.Scan(&field.ID, &field.Name, &field.Type, &field.Options)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(field.Options) == 0 {
+		field.Options = nil
+	}
+
+	return field, nil
+}
+
+func (db *DB) ListCustomFields(ctx context.Context, username string) ([]*CustomField, error) {
+	rows, err := db.conn().Query(ctx, "select "+customFieldColumns+" from custom_fields where username=$1 order by lower(name), id", username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []*CustomField{}
+
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, rows.Err()
+}
+
+func (db *DB) GetCustomField(ctx context.Context, username string, fieldID int64) (*CustomField, error) {
+	return scanCustomField(db.conn().QueryRow(ctx, "select "+customFieldColumns+" from custom_fields where id=$1 and username=$2", fieldID, username))
+}
+
+// Saves a new custom field, setting its ID. Returns ErrConflict if the user already has a field with that name.
+func (db *DB) CreateCustomField(ctx context.Context, username string, field *CustomField) error {
+	err := db.conn().QueryRow(ctx, "insert into custom_fields (username, name, type, options) values ($1, $2, $3, $4) returning id",
+		username,
+		field.Name,
+		field.Type,
+		nonNilStrings(field.Options),
+	).Scan(&field.ID)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+
+	return err
+}
+
+// Renames the field or changes its options, carrying applications' values over to the new name and
+// dropping any that are no longer an option. The type can't be changed, since existing values wouldn't fit.
+func (db *DB) UpdateCustomField(ctx context.Context, username string, field *CustomField) error {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	existing, err := scanCustomField(tx.QueryRow(ctx, "select "+customFieldColumns+" from custom_fields where id=$1 and username=$2 for update", field.ID, username))
+	if err != nil {
+		return err
+	}
+	if existing.Type != field.Type {
+		return ErrCustomFieldType
+	}
+
+	_, err = tx.Exec(ctx, "update custom_fields set name=$1, options=$2 where id=$3", field.Name, nonNilStrings(field.Options), field.ID)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	if field.Type == EnumField {
+		_, err = tx.Exec(ctx, "update applications set custom_fields = custom_fields - $1::text, version=version+1 where username=$2 and custom_fields ? $1::text and not (custom_fields->>$1::text = any($3))",
+			existing.Name,
+			username,
+			field.Options,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if field.Name != existing.Name {
+		_, err = tx.Exec(ctx, "update applications set custom_fields = (custom_fields - $1::text) || jsonb_build_object($2::text, custom_fields->$1::text), version=version+1 where username=$3 and custom_fields ? $1::text",
+			existing.Name,
+			field.Name,
+			username,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Deletes the field and its value on every application.
+func (db *DB) DeleteCustomField(ctx context.Context, username string, fieldID int64) error {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	var name string
+	err = tx.QueryRow(ctx, "delete from custom_fields where id=$1 and username=$2 returning name", fieldID, username).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "update applications set custom_fields = custom_fields - $1::text, version=version+1 where username=$2 and custom_fields ? $1::text", name, username)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Replaces the application's custom field values. The caller validates them with ValidateCustomFieldValues.
+func (db *DB) UpdateApplicationCustomFields(ctx context.Context, username string, companyID string, values map[string]any, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set custom_fields=$1, updated_at=now(), version=version+1
+		where company=$2 and username=$3 and deleted_at is null and ($4::bigint = 0 or version=$4)`,
+		nonNilValues(values),
+		companyID,
+		username,
+		expectedVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Postgres arrays and JSONB objects are not null, so store empty ones rather than nil.
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilValues(values map[string]any) map[string]any {
+	if values == nil {
+		return map[string]any{}
+	}
+	return values
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomFieldValidate(t *testing.T) {
+	field := &CustomField{Name: " Tech stack ", Type: EnumField, Options: []string{" Go ", "Rust"}}
+
+	err := field.Validate()
+	if err != nil {
+		t.Fatalf("Expected a valid field, got %v", err)
+	}
+	if field.Name != "Tech stack" || strings.Join(field.Options, ",") != "Go,Rust" {
+		t.Errorf("Expected the field to be trimmed, got %+v", field)
+	}
+
+	cases := map[string]CustomField{
+		"no name":           {Type: TextField},
+		"bad type":          {Name: "Team size", Type: "integer"},
+		"enum no options":   {Name: "Tech stack", Type: EnumField},
+		"enum empty option": {Name: "Tech stack", Type: EnumField, Options: []string{"Go", " "}},
+		"enum duplicate":    {Name: "Tech stack", Type: EnumField, Options: []string{"Go", "Go"}},
+		"options on text":   {Name: "Team size", Type: NumberField, Options: []string{"1"}},
+	}
+
+	for name, field := range cases {
+		if field.Validate() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateCustomFieldValues(t *testing.T) {
+	schema := []*CustomField{
+		{ID: 1, Name: "Visa sponsorship", Type: BoolField},
+		{ID: 2, Name: "Team size", Type: NumberField},
+		{ID: 3, Name: "Closing date", Type: DateField},
+		{ID: 4, Name: "Tech stack", Type: EnumField, Options: []string{"Go", "Rust"}},
+		{ID: 5, Name: "Hiring manager", Type: TextField},
+	}
+
+	values, err := ValidateCustomFieldValues(schema, map[string]any{
+		"Visa sponsorship": true,
+		"Team size":        float64(12),
+		"Closing date":     " 2025-07-01 ",
+		"Tech stack":       "Go",
+		"Hiring manager":   nil,
+	})
+	if err != nil {
+		t.Fatalf("Expected valid values, got %v", err)
+	}
+	if len(values) != 4 || values["Closing date"] != "2025-07-01" {
+		t.Errorf("Expected normalised values without nulls, got %v", values)
+	}
+
+	invalid := map[string]map[string]any{
+		"unknown field": {"Salary band": "B"},
+		"wrong case":    {"team size": float64(3)},
+		"bool as text":  {"Visa sponsorship": "yes"},
+		"number text":   {"Team size": "twelve"},
+		"bad date":      {"Closing date": "01/07/2025"},
+		"not an option": {"Tech stack": "Java"},
+		"text number":   {"Hiring manager": float64(1)},
+	}
+
+	for name, values := range invalid {
+		_, err := ValidateCustomFieldValues(schema, values)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/medidew/ApplicationTracker/internal/auth"
+)
+
+// Connects to the Postgres at TEST_DATABASE_URL, migrates it and creates a user that's removed after the test.
+// Skips the test when TEST_DATABASE_URL isn't set.
+func setupTestDB(t *testing.T) (*DB, string) {
+	conn_string := os.Getenv("TEST_DATABASE_URL")
+	if conn_string == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, conn_string)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	db := &DB{Pool: pool}
+	_, err = db.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	argon2auth := &auth.Argon2Auth{}
+	err = argon2auth.SetDefaults()
+	if err != nil {
+		t.Fatalf("Failed to set up auth: %v", err)
+	}
+
+	username := fmt.Sprintf("testuser_%d", time.Now().UnixNano())
+	err = db.CreateUser(ctx, username+"@example.com", username, argon2auth, argon2auth.HashPassword([]byte("password")))
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(ctx, "delete from users where username=$1", username)
+	})
+
+	return db, username
+}
+
+func TestDBMissingApplicationIsNotFound(t *testing.T) {
+	db, username := setupTestDB(t)
+	ctx := context.Background()
+
+	err := db.DeleteApplication(ctx, username, "Missing Company", 0)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting a missing application, got %v", err)
+	}
+
+	err = db.UpdateApplicationStatus(ctx, username, "Missing Company", Rejected, 0)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a missing application, got %v", err)
+	}
+
+	err = db.AddApplicationNote(ctx, username, "Missing Company", "Note.", 0)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound adding a note to a missing application, got %v", err)
+	}
+
+	// A batch sees the same error inside a transaction.
+	err = db.WithTx(ctx, func(tx Store) error {
+		return tx.UpdateApplicationStatus(ctx, username, "Missing Company", Rejected, 0)
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound inside WithTx, got %v", err)
+	}
+}
+
+func TestDBUnarchiveRestartsArchiveRuleClock(t *testing.T) {
+	db, username := setupTestDB(t)
+	ctx := context.Background()
+
+	job_application, err := NewJobApplication("Archived Company", SoftwareEngineer, Rejected, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+	err = db.CreateApplication(ctx, username, job_application)
+	if err != nil {
+		t.Fatalf("Failed to create application: %v", err)
+	}
+
+	// Rejected an hour ago, long enough for the rule below.
+	_, err = db.Pool.Exec(ctx, "update applications set status_changed_at = now() - interval '1 hour' where username=$1", username)
+	if err != nil {
+		t.Fatalf("Failed to backdate status: %v", err)
+	}
+
+	err = db.ArchiveApplication(ctx, username, "Archived Company", 0)
+	if err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	err = db.UnarchiveApplication(ctx, username, "Archived Company", 0)
+	if err != nil {
+		t.Fatalf("Failed to unarchive: %v", err)
+	}
+
+	job_application, err = db.GetApplication(ctx, username, "Archived Company")
+	if err != nil {
+		t.Fatalf("Failed to get application: %v", err)
+	}
+	if job_application.unarchivedAt.IsZero() {
+		t.Fatalf("Expected unarchived_at to be scanned")
+	}
+
+	// The status is older than the rule, but unarchiving just now restarted its clock.
+	rule := ArchiveRule{Status: Rejected, After: 30 * time.Minute}
+	if rule.Matches(job_application, time.Now()) {
+		t.Errorf("Expected the rule not to match a just-unarchived application")
+	}
+}
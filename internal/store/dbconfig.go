@@ -1,10 +1,266 @@
 package store
 
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Returned by ResolvePassword when no non-interactive password source is configured.
+var ErrNoPassword = errors.New("no database password configured")
+
+var supportedSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Database connection settings, loaded as the `database` section of the app config.
 type DBConfig struct {
-	Database struct {
-		User string `yaml:"user"`
-		Host string `yaml:"host"`
-		Port string `yaml:"port"`
-		Name string `yaml:"name"`
-	} `yaml:"database"`
+	URL  string `yaml:"url"` // Takes precedence over the individual fields when set, except SSLMode if the URL has none.
+	User string `yaml:"user"`
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+	Name string `yaml:"name"`
+
+	// Password sources, tried in this order by ResolvePassword.
+	Password     string `yaml:"-"`             // Only settable from env, never from the file.
+	PasswordFile string `yaml:"password_file"` // File containing only the password, e.g. a mounted secret.
+	PassFile     string `yaml:"passfile"`      // .pgpass-style file, defaults to PGPASSFILE or ~/.pgpass.
+
+	SSLMode           string        `yaml:"sslmode"`
+	MaxConns          int32         `yaml:"max_conns"` // Zero keeps the pgxpool default.
+	MinConns          int32         `yaml:"min_conns"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+
+	MigrateOnStart bool `yaml:"migrate_on_start"` // Otherwise /readyz fails until migrations are applied.
+}
+
+func (cfg *DBConfig) Validate() error {
+	var errs []error
+
+	if cfg.URL == "" {
+		if cfg.User == "" {
+			errs = append(errs, errors.New("database.user is required"))
+		}
+		if cfg.Host == "" {
+			errs = append(errs, errors.New("database.host is required"))
+		}
+		if cfg.Port == "" {
+			errs = append(errs, errors.New("database.port is required"))
+		}
+		if cfg.Name == "" {
+			errs = append(errs, errors.New("database.name is required"))
+		}
+	}
+
+	if cfg.SSLMode != "" && !slices.Contains(supportedSSLModes, cfg.SSLMode) {
+		errs = append(errs, fmt.Errorf("database.sslmode %q is not one of %s", cfg.SSLMode, strings.Join(supportedSSLModes, ", ")))
+	}
+	if cfg.MaxConns < 0 || cfg.MinConns < 0 {
+		errs = append(errs, errors.New("database.max_conns and database.min_conns must not be negative"))
+	}
+	if cfg.MaxConns > 0 && cfg.MinConns > cfg.MaxConns {
+		errs = append(errs, errors.New("database.min_conns must not exceed database.max_conns"))
+	}
+	if cfg.MaxConnLifetime < 0 || cfg.MaxConnIdleTime < 0 || cfg.HealthCheckPeriod < 0 {
+		errs = append(errs, errors.New("database connection durations must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Finds the database password without prompting, trying the env var, the password file and then the passfile.
+// Returns ErrNoPassword if none of them provide one.
+func (cfg *DBConfig) ResolvePassword() (string, error) {
+	if cfg.Password != "" {
+		return cfg.Password, nil
+	}
+
+	if cfg.PasswordFile != "" {
+		password_file, err := os.ReadFile(cfg.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read database.password_file: %w", err)
+		}
+		return strings.TrimRight(string(password_file), "\r\n"), nil
+	}
+
+	pass_file_path := cfg.PassFile
+	explicit := pass_file_path != ""
+	if !explicit {
+		pass_file_path = os.Getenv("PGPASSFILE")
+		explicit = pass_file_path != ""
+	}
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ErrNoPassword
+		}
+		pass_file_path = filepath.Join(home, ".pgpass")
+	}
+
+	pass_file, err := os.Open(pass_file_path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return "", ErrNoPassword
+		}
+		return "", fmt.Errorf("failed to open passfile: %w", err)
+	}
+	defer pass_file.Close()
+
+	password, found, err := lookupPassFile(pass_file, cfg.Host, cfg.Port, cfg.Name, cfg.User)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrNoPassword
+	}
+
+	return password, nil
+}
+
+// Searches a .pgpass-style file (`host:port:database:user:password`, `*` matches anything) for the first matching entry.
+func lookupPassFile(pass_file io.Reader, host string, port string, database string, user string) (string, bool, error) {
+	scanner := bufio.NewScanner(pass_file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitPassFileLine(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		if passFileFieldMatches(fields[0], host) &&
+			passFileFieldMatches(fields[1], port) &&
+			passFileFieldMatches(fields[2], database) &&
+			passFileFieldMatches(fields[3], user) {
+			return fields[4], true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", false, fmt.Errorf("failed to read passfile: %w", err)
+	}
+
+	return "", false, nil
+}
+
+// Splits on unescaped colons, unescaping `\:` and `\\`.
+func splitPassFileLine(line string) []string {
+	fields := []string{}
+	var field strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+
+	return append(fields, field.String())
+}
+
+func passFileFieldMatches(pattern string, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// Builds a postgresql:// URL from the individual fields, escaping the user and password.
+func (cfg *DBConfig) ConnString(password string) string {
+	conn_url := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(cfg.User, password),
+		Host:   net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:   "/" + cfg.Name,
+	}
+
+	if cfg.SSLMode != "" {
+		conn_url.RawQuery = url.Values{"sslmode": {cfg.SSLMode}}.Encode()
+	}
+
+	return conn_url.String()
+}
+
+// Adds `sslmode` to a URL or keyword/value connection string that doesn't already set one.
+func withSSLMode(conn_string string, sslmode string) (string, error) {
+	if sslmode == "" {
+		return conn_string, nil
+	}
+
+	if !strings.HasPrefix(conn_string, "postgres://") && !strings.HasPrefix(conn_string, "postgresql://") {
+		if strings.Contains(conn_string, "sslmode=") {
+			return conn_string, nil
+		}
+		return conn_string + " sslmode=" + sslmode, nil
+	}
+
+	conn_url, err := url.Parse(conn_string)
+	if err != nil {
+		return "", err
+	}
+
+	query := conn_url.Query()
+	if query.Get("sslmode") == "" {
+		query.Set("sslmode", sslmode)
+		conn_url.RawQuery = query.Encode()
+	}
+
+	return conn_url.String(), nil
+}
+
+// Builds the pgxpool config, from URL if set or the individual fields otherwise, then applies the pool settings.
+func (cfg *DBConfig) PoolConfig(password string) (*pgxpool.Config, error) {
+	pool_config, err := cfg.parseConnString(password)
+	if err != nil {
+		// The parse error can echo the connection string, so don't wrap it.
+		return nil, errors.New("failed to parse database connection settings")
+	}
+
+	if cfg.MaxConns > 0 {
+		pool_config.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		pool_config.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		pool_config.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		pool_config.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		pool_config.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	return pool_config, nil
+}
+
+// Parses URL, with SSLMode applied, if set or the individual fields otherwise. Errors may echo the connection string.
+func (cfg *DBConfig) parseConnString(password string) (*pgxpool.Config, error) {
+	if cfg.URL == "" {
+		return pgxpool.ParseConfig(cfg.ConnString(password))
+	}
+
+	conn_string, err := withSSLMode(cfg.URL, cfg.SSLMode)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.ParseConfig(conn_string)
 }
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Returned when the requested record doesn't exist or belongs to another user.
+var ErrNotFound = errors.New("not found")
+
+// Returned when a record would duplicate one the user already has, such as a tag name.
+var ErrConflict = errors.New("already exists")
+
+// Returned when an update's expected version no longer matches the record, because someone else changed it.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// Returned when creating an application whose company is in the trash. It's an ErrConflict.
+var ErrTrashed = fmt.Errorf("%w: the application is in the trash", ErrConflict)
+
+// Returned when an idempotency key is reused for a different request.
+var ErrIdempotencyMismatch = errors.New("idempotency key was used for a different request")
+
+// Returned when the first request with an idempotency key hasn't finished yet.
+var ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// Reports whether `err` is Postgres rejecting a row that breaks a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pg_error *pgconn.PgError
+	return errors.As(err, &pg_error) && pg_error.Code == "23505"
+}
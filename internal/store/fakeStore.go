@@ -1,68 +1,362 @@
 package store
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/medidew/ApplicationTracker/internal/auth"
 )
 
 type FakeStore struct {
 	Applications map[string][]*JobApplication
+	Trash        map[string][]*JobApplication // Deleted applications, kept apart so nothing else sees them.
+	Contacts     map[string][]*Contact
+	ContactLinks map[string]map[string][]int64 // Contact IDs by username, then company.
+	CustomFields map[string][]*CustomField
+	Tags         map[string][]*Tag
+	TagLinks     map[string]map[string][]int64 // Tag IDs by username, then company.
+	Interviews   map[string][]*Interview
+	Offers       map[string][]*JobOffer
+	Attachments  map[string][]*Attachment
+
+	idempotencyKeys map[string]map[string]*fakeIdempotencyKey // By username, then key.
+
+	nextContactID    int64
+	nextTagID        int64
+	nextFieldID      int64
+	nextInterviewID  int64
+	nextOfferID      int64
+	nextAttachmentID int64
 }
 
 func NewFakeStore(applications map[string][]*JobApplication) *FakeStore {
 	return &FakeStore{
 		Applications: applications,
+		Trash:        map[string][]*JobApplication{},
+		Contacts:     map[string][]*Contact{},
+		ContactLinks: map[string]map[string][]int64{},
+		CustomFields: map[string][]*CustomField{},
+		Tags:         map[string][]*Tag{},
+		TagLinks:     map[string]map[string][]int64{},
+		Interviews:   map[string][]*Interview{},
+		Offers:       map[string][]*JobOffer{},
+		Attachments:  map[string][]*Attachment{},
+
+		idempotencyKeys: map[string]map[string]*fakeIdempotencyKey{},
+	}
+}
+
+func (fs *FakeStore) ListApplications(ctx context.Context, username string, filter ApplicationFilter) ([]*JobApplication, error) {
+	applications := []*JobApplication{}
+
+	for _, application := range fs.Applications[username] {
+		if fs.matches(username, application, filter) {
+			applications = append(applications, application)
+		}
+	}
+
+	return applications, nil
+}
+
+func (fs *FakeStore) StreamApplications(ctx context.Context, username string, filter ApplicationFilter, yield func(*JobApplication) error) error {
+	for _, application := range fs.Applications[username] {
+		if !fs.matches(username, application, filter) {
+			continue
+		}
+
+		err := yield(application)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func (fs *FakeStore) ListApplications(username string) ([]*JobApplication, error) {
-	return fs.Applications[username], nil
+func (fs *FakeStore) matches(username string, application *JobApplication, filter ApplicationFilter) bool {
+	if !filter.Matches(application) {
+		return false
+	}
+
+	tag_names := []string{}
+	for _, tag_id := range fs.TagLinks[username][application.GetCompany()] {
+		tag, err := fs.GetTag(context.Background(), username, tag_id)
+		if err == nil {
+			tag_names = append(tag_names, tag.Name)
+		}
+	}
+
+	return filter.MatchesTags(tag_names)
 }
 
-func (fs *FakeStore) GetApplication(username string, companyID string) (*JobApplication, error) {
+func (fs *FakeStore) SearchApplications(ctx context.Context, username string, query string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	results := []SearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	for _, application := range fs.Applications[username] {
+		result, ok := searchApplication(application, terms)
+		if ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func (fs *FakeStore) GetApplication(ctx context.Context, username string, companyID string) (*JobApplication, error) {
 	for _, application := range fs.Applications[username] {
 		if application.GetCompany() == companyID {
 			return application, nil
 		}
 	}
 
-	return nil, errors.New("application not found")
+	return nil, ErrNotFound
 }
 
-func (fs *FakeStore) CreateApplication(username string, application *JobApplication) error {
+func (fs *FakeStore) CreateApplication(ctx context.Context, username string, application *JobApplication) error {
+	if fs.trashed(username, application.company) {
+		return ErrTrashed
+	}
 	for _, existing_application := range fs.Applications[username] {
 		if existing_application.GetCompany() == application.company {
-			return errors.New("application already exists")
+			return ErrConflict
 		}
 	}
 
+	now := time.Now()
+	application.SetTimestamps(now, now)
+	application.SetStatusChangedAt(now)
+	application.version = 1 // As the column default, since applications decoded from JSON start at 0.
+
 	fs.Applications[username] = append(fs.Applications[username], application)
 	return nil
 }
 
-func (fs *FakeStore) DeleteApplication(username string, companyID string) error {
-	for i, application := range fs.Applications[username] {
+func (fs *FakeStore) DeleteApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	fs.Applications[username] = slices.DeleteFunc(fs.Applications[username], func(existing *JobApplication) bool {
+		return existing == application
+	})
+	application.SetDeletedAt(time.Now())
+	application.version++
+	fs.Trash[username] = append(fs.Trash[username], application)
+	return nil
+}
+
+// Reports whether the user's application for `companyID` is in the trash.
+func (fs *FakeStore) trashed(username string, companyID string) bool {
+	return slices.ContainsFunc(fs.Trash[username], func(application *JobApplication) bool {
+		return application.GetCompany() == companyID
+	})
+}
+
+// Finds the live application, checking it's still at `expectedVersion` unless that's 0.
+// Returns ErrNotFound if there's no such application, like the DB.
+func (fs *FakeStore) versionedApplication(username string, companyID string, expectedVersion int64) (*JobApplication, error) {
+	for _, application := range fs.Applications[username] {
+		if application.GetCompany() != companyID {
+			continue
+		}
+		if expectedVersion != 0 && application.version != expectedVersion {
+			return nil, ErrVersionMismatch
+		}
+		return application, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+func (fs *FakeStore) ArchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if application.GetArchivedAt().IsZero() {
+		application.SetArchivedAt(time.Now())
+	}
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) UnarchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if !application.GetArchivedAt().IsZero() {
+		application.SetArchivedAt(time.Time{})
+		application.unarchivedAt = time.Now()
+	}
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) ArchiveStaleApplications(ctx context.Context, rules []ArchiveRule, now time.Time) (int64, error) {
+	archived := int64(0)
+
+	for _, rule := range rules {
+		err := rule.Validate()
+		if err != nil {
+			return archived, err
+		}
+
+		for _, applications := range fs.Applications {
+			for _, application := range applications {
+				if rule.Matches(application, now) {
+					application.SetArchivedAt(now)
+					application.version++
+					archived++
+				}
+			}
+		}
+	}
+
+	return archived, nil
+}
+
+func (fs *FakeStore) ListTrashedApplications(ctx context.Context, username string) ([]*JobApplication, error) {
+	applications := slices.Clone(fs.Trash[username])
+	sort.SliceStable(applications, func(i, j int) bool {
+		return applications[i].GetDeletedAt().After(applications[j].GetDeletedAt())
+	})
+
+	return applications, nil
+}
+
+func (fs *FakeStore) RestoreApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	for i, application := range fs.Trash[username] {
 		if application.GetCompany() == companyID {
-			fs.Applications[username] = append(fs.Applications[username][:i], fs.Applications[username][i+1:]...)
+			if expectedVersion != 0 && application.version != expectedVersion {
+				return ErrVersionMismatch
+			}
+			fs.Trash[username] = slices.Delete(fs.Trash[username], i, i+1)
+			application.SetDeletedAt(time.Time{})
+			application.version++
+			fs.Applications[username] = append(fs.Applications[username], application)
 			return nil
 		}
 	}
 
-	return errors.New("application not found")
+	return ErrNotFound
 }
 
-func (fs *FakeStore) UpdateApplicationStatus(username string, companyID string, status ApplicationStatus) error {
-	for _, application := range fs.Applications[username] {
-		if application.GetCompany() == companyID {
-			return application.UpdateStatus(status)
+func (fs *FakeStore) PurgeDeletedApplications(ctx context.Context, deletedBefore time.Time) (int64, []string, error) {
+	purged := int64(0)
+	storage_keys := []string{}
+
+	for username, applications := range fs.Trash {
+		fs.Trash[username] = slices.DeleteFunc(applications, func(application *JobApplication) bool {
+			if !application.GetDeletedAt().Before(deletedBefore) {
+				return false
+			}
+
+			purged++
+			storage_keys = append(storage_keys, fs.purgeApplication(username, application.GetCompany())...)
+			return true
+		})
+	}
+
+	return purged, storage_keys, nil
+}
+
+// Removes everything attached to the application, as the foreign keys cascade in Postgres.
+// Returns the storage keys of its attachments.
+func (fs *FakeStore) purgeApplication(username string, companyID string) []string {
+	storage_keys := []string{}
+
+	delete(fs.ContactLinks[username], companyID)
+	delete(fs.TagLinks[username], companyID)
+	fs.Interviews[username] = slices.DeleteFunc(fs.Interviews[username], func(interview *Interview) bool {
+		return interview.Company == companyID
+	})
+	fs.Offers[username] = slices.DeleteFunc(fs.Offers[username], func(offer *JobOffer) bool {
+		return offer.Company == companyID
+	})
+	fs.Attachments[username] = slices.DeleteFunc(fs.Attachments[username], func(attachment *Attachment) bool {
+		if attachment.Company != companyID {
+			return false
 		}
+
+		storage_keys = append(storage_keys, attachment.StorageKey)
+		return true
+	})
+
+	return storage_keys
+}
+
+func (fs *FakeStore) UpdateApplicationStatus(ctx context.Context, username string, companyID string, status ApplicationStatus, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
 	}
 
-	return errors.New("application not found")
+	err = application.UpdateStatus(status)
+	if err != nil {
+		return err
+	}
+	application.version++
+	return nil
 }
 
-func (fs *FakeStore) ListApplicationNotes(username string, companyID string) ([]string, error) {
+func (fs *FakeStore) SetApplicationFollowUp(ctx context.Context, username string, companyID string, followUpAt time.Time, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	application.SetFollowUpAt(followUpAt)
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) UpdateApplicationDetails(ctx context.Context, username string, companyID string, details ApplicationDetails, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	err = application.SetDetails(details)
+	if err != nil {
+		return err
+	}
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) UpdateApplicationCustomFields(ctx context.Context, username string, companyID string, values map[string]any, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	application.SetCustomFields(values)
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) ListApplicationNotes(ctx context.Context, username string, companyID string) ([]string, error) {
 	for _, application := range fs.Applications[username] {
 		if application.GetCompany() == companyID {
 			return application.GetNotes(), nil
@@ -72,38 +366,725 @@ func (fs *FakeStore) ListApplicationNotes(username string, companyID string) ([]
 	return nil, errors.New("application not found")
 }
 
-func (fs *FakeStore) AddApplicationNote(username string, companyID string, note string) error {
-	for _, application := range fs.Applications[username] {
-		if application.GetCompany() == companyID {
-			application.AddNote(note)
+func (fs *FakeStore) AddApplicationNote(ctx context.Context, username string, companyID string, note string, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	application.AddNote(note)
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) RemoveApplicationNote(ctx context.Context, username string, companyID string, index int, expectedVersion int64) error {
+	application, err := fs.versionedApplication(username, companyID, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	err = application.RemoveNote(index)
+	if err != nil {
+		return err
+	}
+	application.version++
+	return nil
+}
+
+func (fs *FakeStore) ImportApplications(ctx context.Context, username string, applications []*JobApplication, dryRun bool) ([]ImportOutcome, error) {
+	existing := map[string]bool{}
+	for _, application := range slices.Concat(fs.Applications[username], fs.Trash[username]) {
+		existing[application.GetCompany()] = true
+	}
+
+	outcomes := make([]ImportOutcome, 0, len(applications))
+	created := []*JobApplication{}
+
+	for _, application := range applications {
+		if existing[application.GetCompany()] {
+			outcomes = append(outcomes, ImportSkipped)
+			continue
+		}
+
+		existing[application.GetCompany()] = true
+		now := time.Now()
+		application.SetTimestamps(now, now)
+		application.SetStatusChangedAt(now)
+		application.version = 1
+		created = append(created, application)
+		outcomes = append(outcomes, ImportCreated)
+	}
+
+	if !dryRun {
+		fs.Applications[username] = append(fs.Applications[username], created...)
+	}
+
+	return outcomes, nil
+}
+
+func (fs *FakeStore) ListContacts(ctx context.Context, username string) ([]*Contact, error) {
+	contacts := slices.Clone(fs.Contacts[username])
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return contacts[i].Name < contacts[j].Name
+	})
+
+	return contacts, nil
+}
+
+func (fs *FakeStore) GetContact(ctx context.Context, username string, contactID int64) (*Contact, error) {
+	for _, contact := range fs.Contacts[username] {
+		if contact.ID == contactID {
+			return contact, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (fs *FakeStore) CreateContact(ctx context.Context, username string, contact *Contact) error {
+	fs.nextContactID++
+	contact.ID = fs.nextContactID
+
+	fs.Contacts[username] = append(fs.Contacts[username], contact)
+	return nil
+}
+
+func (fs *FakeStore) UpdateContact(ctx context.Context, username string, contact *Contact) error {
+	for i, existing_contact := range fs.Contacts[username] {
+		if existing_contact.ID == contact.ID {
+			fs.Contacts[username][i] = contact
 			return nil
 		}
 	}
 
-	return errors.New("application not found")
+	return ErrNotFound
 }
 
-func (fs *FakeStore) RemoveApplicationNote(username string, companyID string, index int) error {
-	for _, application := range fs.Applications[username] {
-		if application.GetCompany() == companyID {
-			return application.RemoveNote(index)
+func (fs *FakeStore) DeleteContact(ctx context.Context, username string, contactID int64) error {
+	for i, contact := range fs.Contacts[username] {
+		if contact.ID == contactID {
+			fs.Contacts[username] = slices.Delete(fs.Contacts[username], i, i+1)
+
+			for company, contact_ids := range fs.ContactLinks[username] {
+				fs.ContactLinks[username][company] = slices.DeleteFunc(contact_ids, func(id int64) bool { return id == contactID })
+			}
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) ListApplicationContacts(ctx context.Context, username string, companyID string) ([]*Contact, error) {
+	contacts := []*Contact{}
+
+	for _, contact_id := range fs.ContactLinks[username][companyID] {
+		contact, err := fs.GetContact(ctx, username, contact_id)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return contacts[i].Name < contacts[j].Name
+	})
+
+	return contacts, nil
+}
+
+func (fs *FakeStore) LinkContact(ctx context.Context, username string, companyID string, contactID int64) error {
+	_, err := fs.GetApplication(ctx, username, companyID)
+	if err != nil {
+		return ErrNotFound
+	}
+	_, err = fs.GetContact(ctx, username, contactID)
+	if err != nil {
+		return err
+	}
+
+	if fs.ContactLinks[username] == nil {
+		fs.ContactLinks[username] = map[string][]int64{}
+	}
+	if !slices.Contains(fs.ContactLinks[username][companyID], contactID) {
+		fs.ContactLinks[username][companyID] = append(fs.ContactLinks[username][companyID], contactID)
+	}
+
+	return nil
+}
+
+func (fs *FakeStore) UnlinkContact(ctx context.Context, username string, companyID string, contactID int64) error {
+	contact_ids := fs.ContactLinks[username][companyID]
+
+	i := slices.Index(contact_ids, contactID)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	fs.ContactLinks[username][companyID] = slices.Delete(contact_ids, i, i+1)
+	return nil
+}
+
+func (fs *FakeStore) ListCustomFields(ctx context.Context, username string) ([]*CustomField, error) {
+	fields := slices.Clone(fs.CustomFields[username])
+	if fields == nil {
+		fields = []*CustomField{}
+	}
+
+	slices.SortStableFunc(fields, func(a *CustomField, b *CustomField) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return fields, nil
+}
+
+func (fs *FakeStore) GetCustomField(ctx context.Context, username string, fieldID int64) (*CustomField, error) {
+	for _, field := range fs.CustomFields[username] {
+		if field.ID == fieldID {
+			return field, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Reports whether another of the user's custom fields has `name`, ignoring case.
+func (fs *FakeStore) customFieldNameTaken(username string, name string, fieldID int64) bool {
+	return slices.ContainsFunc(fs.CustomFields[username], func(field *CustomField) bool {
+		return field.ID != fieldID && strings.EqualFold(field.Name, name)
+	})
+}
+
+func (fs *FakeStore) CreateCustomField(ctx context.Context, username string, field *CustomField) error {
+	if fs.customFieldNameTaken(username, field.Name, 0) {
+		return ErrConflict
+	}
+
+	fs.nextFieldID++
+	field.ID = fs.nextFieldID
+
+	fs.CustomFields[username] = append(fs.CustomFields[username], field)
+	return nil
+}
+
+func (fs *FakeStore) UpdateCustomField(ctx context.Context, username string, field *CustomField) error {
+	for i, existing := range fs.CustomFields[username] {
+		if existing.ID != field.ID {
+			continue
+		}
+		if existing.Type != field.Type {
+			return ErrCustomFieldType
+		}
+		if fs.customFieldNameTaken(username, field.Name, field.ID) {
+			return ErrConflict
+		}
+
+		for _, application := range slices.Concat(fs.Applications[username], fs.Trash[username]) {
+			value, ok := application.customFields[existing.Name]
+			if !ok {
+				continue
+			}
+
+			delete(application.customFields, existing.Name)
+			keep := field.Type != EnumField || slices.Contains(field.Options, value.(string))
+			if keep {
+				application.customFields[field.Name] = value
+			}
+			if !keep || field.Name != existing.Name {
+				application.version++
+			}
+		}
+
+		fs.CustomFields[username][i] = field
+		return nil
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) DeleteCustomField(ctx context.Context, username string, fieldID int64) error {
+	for i, field := range fs.CustomFields[username] {
+		if field.ID == fieldID {
+			fs.CustomFields[username] = slices.Delete(fs.CustomFields[username], i, i+1)
+
+			for _, application := range slices.Concat(fs.Applications[username], fs.Trash[username]) {
+				if _, ok := application.customFields[field.Name]; ok {
+					delete(application.customFields, field.Name)
+					application.version++
+				}
+			}
+			return nil
 		}
 	}
 
-	return errors.New("application not found")
+	return ErrNotFound
 }
 
-func (fs *FakeStore) CreateUser(email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error {
+func (fs *FakeStore) ListTags(ctx context.Context, username string) ([]*Tag, error) {
+	tags := slices.Clone(fs.Tags[username])
+	if tags == nil {
+		tags = []*Tag{}
+	}
+
+	slices.SortStableFunc(tags, func(a *Tag, b *Tag) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return tags, nil
+}
+
+func (fs *FakeStore) GetTag(ctx context.Context, username string, tagID int64) (*Tag, error) {
+	for _, tag := range fs.Tags[username] {
+		if tag.ID == tagID {
+			return tag, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Reports whether another of the user's tags has `name`, ignoring case.
+func (fs *FakeStore) tagNameTaken(username string, name string, tagID int64) bool {
+	return slices.ContainsFunc(fs.Tags[username], func(tag *Tag) bool {
+		return tag.ID != tagID && strings.EqualFold(tag.Name, name)
+	})
+}
+
+func (fs *FakeStore) CreateTag(ctx context.Context, username string, tag *Tag) error {
+	if fs.tagNameTaken(username, tag.Name, 0) {
+		return ErrConflict
+	}
+
+	fs.nextTagID++
+	tag.ID = fs.nextTagID
+
+	fs.Tags[username] = append(fs.Tags[username], tag)
+	return nil
+}
+
+func (fs *FakeStore) UpdateTag(ctx context.Context, username string, tag *Tag) error {
+	for i, existing_tag := range fs.Tags[username] {
+		if existing_tag.ID == tag.ID {
+			if fs.tagNameTaken(username, tag.Name, tag.ID) {
+				return ErrConflict
+			}
+			fs.Tags[username][i] = tag
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) DeleteTag(ctx context.Context, username string, tagID int64) error {
+	for i, tag := range fs.Tags[username] {
+		if tag.ID == tagID {
+			fs.Tags[username] = slices.Delete(fs.Tags[username], i, i+1)
+
+			for company, tag_ids := range fs.TagLinks[username] {
+				fs.TagLinks[username][company] = slices.DeleteFunc(tag_ids, func(id int64) bool { return id == tagID })
+			}
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) ListApplicationTags(ctx context.Context, username string, companyID string) ([]*Tag, error) {
+	tags := []*Tag{}
+
+	for _, tag_id := range fs.TagLinks[username][companyID] {
+		tag, err := fs.GetTag(ctx, username, tag_id)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.SortStableFunc(tags, func(a *Tag, b *Tag) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return tags, nil
+}
+
+func (fs *FakeStore) TagApplication(ctx context.Context, username string, companyID string, tagID int64) error {
+	_, err := fs.GetApplication(ctx, username, companyID)
+	if err != nil {
+		return ErrNotFound
+	}
+	_, err = fs.GetTag(ctx, username, tagID)
+	if err != nil {
+		return err
+	}
+
+	if fs.TagLinks[username] == nil {
+		fs.TagLinks[username] = map[string][]int64{}
+	}
+	if !slices.Contains(fs.TagLinks[username][companyID], tagID) {
+		fs.TagLinks[username][companyID] = append(fs.TagLinks[username][companyID], tagID)
+	}
+
+	return nil
+}
+
+func (fs *FakeStore) UntagApplication(ctx context.Context, username string, companyID string, tagID int64) error {
+	tag_ids := fs.TagLinks[username][companyID]
+
+	i := slices.Index(tag_ids, tagID)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	fs.TagLinks[username][companyID] = slices.Delete(tag_ids, i, i+1)
+	return nil
+}
+
+func (fs *FakeStore) ListInterviews(ctx context.Context, username string, filter InterviewFilter) ([]*Interview, error) {
+	interviews := []*Interview{}
+
+	for _, interview := range fs.Interviews[username] {
+		// Interviews for trashed applications are hidden along with them.
+		if filter.Matches(interview) && !fs.trashed(username, interview.Company) {
+			interviews = append(interviews, interview)
+		}
+	}
+
+	sort.SliceStable(interviews, func(i, j int) bool {
+		return interviews[i].ScheduledAt.Before(interviews[j].ScheduledAt)
+	})
+	if filter.Limit > 0 && len(interviews) > filter.Limit {
+		interviews = interviews[:filter.Limit]
+	}
+
+	return interviews, nil
+}
+
+func (fs *FakeStore) GetInterview(ctx context.Context, username string, companyID string, interviewID int64) (*Interview, error) {
+	for _, interview := range fs.Interviews[username] {
+		if interview.ID == interviewID && interview.Company == companyID && !fs.trashed(username, companyID) {
+			return interview, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (fs *FakeStore) CreateInterview(ctx context.Context, username string, interview *Interview, advanceStatus bool) error {
+	application, err := fs.GetApplication(ctx, username, interview.Company)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	fs.nextInterviewID++
+	interview.ID = fs.nextInterviewID
+	fs.Interviews[username] = append(fs.Interviews[username], interview)
+
+	if advanceStatus && application.GetStatus() == PendingResponse {
+		application.version++
+		return application.UpdateStatus(Active)
+	}
+
+	return nil
+}
+
+func (fs *FakeStore) UpdateInterview(ctx context.Context, username string, interview *Interview) error {
+	for i, existing_interview := range fs.Interviews[username] {
+		if existing_interview.ID == interview.ID && existing_interview.Company == interview.Company && !fs.trashed(username, interview.Company) {
+			fs.Interviews[username][i] = interview
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) DeleteInterview(ctx context.Context, username string, companyID string, interviewID int64) error {
+	for i, interview := range fs.Interviews[username] {
+		if interview.ID == interviewID && interview.Company == companyID && !fs.trashed(username, companyID) {
+			fs.Interviews[username] = slices.Delete(fs.Interviews[username], i, i+1)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) ListOffers(ctx context.Context, username string, companyID string) ([]*JobOffer, error) {
+	offers := []*JobOffer{}
+
+	for _, offer := range fs.Offers[username] {
+		if (companyID == "" || offer.Company == companyID) && !fs.trashed(username, offer.Company) {
+			offers = append(offers, offer)
+		}
+	}
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].Company < offers[j].Company
+	})
+
+	return offers, nil
+}
+
+func (fs *FakeStore) GetOffer(ctx context.Context, username string, companyID string, offerID int64) (*JobOffer, error) {
+	for _, offer := range fs.Offers[username] {
+		if offer.ID == offerID && offer.Company == companyID && !fs.trashed(username, companyID) {
+			return offer, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (fs *FakeStore) CreateOffer(ctx context.Context, username string, offer *JobOffer) error {
+	_, err := fs.GetApplication(ctx, username, offer.Company)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	fs.nextOfferID++
+	offer.ID = fs.nextOfferID
+	fs.Offers[username] = append(fs.Offers[username], offer)
+
+	return nil
+}
+
+func (fs *FakeStore) UpdateOffer(ctx context.Context, username string, offer *JobOffer) error {
+	for i, existing_offer := range fs.Offers[username] {
+		if existing_offer.ID == offer.ID && existing_offer.Company == offer.Company && !fs.trashed(username, offer.Company) {
+			fs.Offers[username][i] = offer
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) DeleteOffer(ctx context.Context, username string, companyID string, offerID int64) error {
+	for i, offer := range fs.Offers[username] {
+		if offer.ID == offerID && offer.Company == companyID && !fs.trashed(username, companyID) {
+			fs.Offers[username] = slices.Delete(fs.Offers[username], i, i+1)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (fs *FakeStore) ListAttachments(ctx context.Context, username string, companyID string) ([]*Attachment, error) {
+	attachments := []*Attachment{}
+
+	for _, attachment := range fs.Attachments[username] {
+		if attachment.Company == companyID && !fs.trashed(username, companyID) {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	return attachments, nil
+}
+
+func (fs *FakeStore) GetAttachment(ctx context.Context, username string, companyID string, attachmentID int64) (*Attachment, error) {
+	for _, attachment := range fs.Attachments[username] {
+		if attachment.ID == attachmentID && attachment.Company == companyID && !fs.trashed(username, companyID) {
+			return attachment, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (fs *FakeStore) CreateAttachment(ctx context.Context, username string, attachment *Attachment) error {
+	_, err := fs.GetApplication(ctx, username, attachment.Company)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	fs.nextAttachmentID++
+	attachment.ID = fs.nextAttachmentID
+	attachment.CreatedAt = time.Now()
+	fs.Attachments[username] = append(fs.Attachments[username], attachment)
+
+	return nil
+}
+
+func (fs *FakeStore) DeleteAttachment(ctx context.Context, username string, companyID string, attachmentID int64) error {
+	for i, attachment := range fs.Attachments[username] {
+		if attachment.ID == attachmentID && attachment.Company == companyID && !fs.trashed(username, companyID) {
+			fs.Attachments[username] = slices.Delete(fs.Attachments[username], i, i+1)
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+type fakeIdempotencyKey struct {
+	requestHash []byte
+	response    *IdempotentResponse // Nil while the first request is still being handled.
+	expiresAt   time.Time
+}
+
+func (fs *FakeStore) BeginIdempotentRequest(ctx context.Context, username string, key string, requestHash []byte, expiresAt time.Time) (*IdempotentResponse, error) {
+	if fs.idempotencyKeys[username] == nil {
+		fs.idempotencyKeys[username] = map[string]*fakeIdempotencyKey{}
+	}
+
+	existing := fs.idempotencyKeys[username][key]
+	if existing == nil || !existing.expiresAt.After(time.Now()) {
+		fs.idempotencyKeys[username][key] = &fakeIdempotencyKey{requestHash: requestHash, expiresAt: expiresAt}
+		return nil, nil
+	}
+
+	if !bytes.Equal(existing.requestHash, requestHash) {
+		return nil, ErrIdempotencyMismatch
+	}
+	if existing.response == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	return existing.response, nil
+}
+
+func (fs *FakeStore) CompleteIdempotentRequest(ctx context.Context, username string, key string, response *IdempotentResponse) error {
+	if existing := fs.idempotencyKeys[username][key]; existing != nil {
+		existing.response = response
+	}
+
+	return nil
+}
+
+func (fs *FakeStore) ReleaseIdempotentRequest(ctx context.Context, username string, key string) error {
+	if existing := fs.idempotencyKeys[username][key]; existing != nil && existing.response == nil {
+		delete(fs.idempotencyKeys[username], key)
+	}
+
+	return nil
+}
+
+func (fs *FakeStore) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	var purged int64
+
+	for _, keys := range fs.idempotencyKeys {
+		for key, existing := range keys {
+			if !existing.expiresAt.After(now) {
+				delete(keys, key)
+				purged++
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+func (fs *FakeStore) CreateUser(ctx context.Context, email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error {
 	// FakeStore does not yet implement user storage.
 	return nil
 }
 
-func (fs *FakeStore) GetUserHashedPassword(username string) ([]byte, error) {
+func (fs *FakeStore) GetUserHashedPassword(ctx context.Context, username string) ([]byte, error) {
 	// FakeStore does not yet implement user storage.
 	return nil, nil
 }
 
-func (fs *FakeStore) GetUserArgon2Auth(username string) (*auth.Argon2Auth, error) {
+func (fs *FakeStore) GetUserArgon2Auth(ctx context.Context, username string) (*auth.Argon2Auth, error) {
 	// FakeStore does not yet implement user storage.
 	return nil, nil
+}
+
+// Runs `fn` against the fake itself, putting every record back as it was if `fn` fails.
+// Like Postgres sequences, the ID counters aren't rolled back.
+func (fs *FakeStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	restores := []func(){
+		snapshotRecords(&fs.Applications, cloneApplication),
+		snapshotRecords(&fs.Trash, cloneApplication),
+		snapshotRecords(&fs.Contacts, nil),
+		snapshotRecords(&fs.CustomFields, nil),
+		snapshotRecords(&fs.Tags, nil),
+		snapshotRecords(&fs.Interviews, nil),
+		snapshotRecords(&fs.Offers, nil),
+		snapshotRecords(&fs.Attachments, nil),
+		snapshotLinks(&fs.ContactLinks),
+		snapshotLinks(&fs.TagLinks),
+	}
+
+	err := fn(fs)
+	if err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+
+	return err
+}
+
+// Copies the slices in `records` and each record's value, using `clone` if it isn't nil, and returns a func
+// that puts them back. Values are restored in place, so pointers to records held elsewhere see the rollback.
+func snapshotRecords[T any](records *map[string][]*T, clone func(T) T) func() {
+	saved_records := make(map[string][]*T, len(*records))
+	saved_values := map[*T]T{}
+
+	for username, user_records := range *records {
+		saved_records[username] = slices.Clone(user_records)
+		for _, record := range user_records {
+			if clone != nil {
+				saved_values[record] = clone(*record)
+			} else {
+				saved_values[record] = *record
+			}
+		}
+	}
+
+	return func() {
+		for record, value := range saved_values {
+			*record = value
+		}
+		*records = saved_records
+	}
+}
+
+// Notes and custom fields are changed in place, so they need copies of their own.
+func cloneApplication(application JobApplication) JobApplication {
+	application.notes = slices.Clone(application.notes)
+	application.customFields = maps.Clone(application.customFields)
+	return application
+}
+
+func snapshotLinks(links *map[string]map[string][]int64) func() {
+	saved_links := make(map[string]map[string][]int64, len(*links))
+	for username, companies := range *links {
+		saved_links[username] = make(map[string][]int64, len(companies))
+		for company, ids := range companies {
+			saved_links[username][company] = slices.Clone(ids)
+		}
+	}
+
+	return func() {
+		*links = saved_links
+	}
+}
+
+func (fs *FakeStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (fs *FakeStore) PendingMigrations(ctx context.Context) ([]string, error) {
+	// FakeStore has no schema to migrate.
+	return []string{}, nil
+}
+
+func (fs *FakeStore) GetApplicationStats(ctx context.Context, username string, from time.Time, to time.Time) (*ApplicationStats, error) {
+	return computeApplicationStats(fs.Applications[username], from, to), nil
+}
+
+func (fs *FakeStore) CountApplicationsByStatus(ctx context.Context) (map[ApplicationStatus]int, error) {
+	counts := map[ApplicationStatus]int{}
+
+	for _, applications := range fs.Applications {
+		for _, application := range applications {
+			counts[application.GetStatus()]++
+		}
+	}
+
+	return counts, nil
 }
\ No newline at end of file
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Whether archived applications pass an ApplicationFilter.
+type ArchivedFilter byte
+
+const (
+	AnyArchived  ArchivedFilter = iota // Archived or not.
+	NotArchived                        // Only the active pipeline.
+	OnlyArchived
+)
+
+// Narrows which applications are listed or exported. Zero values match everything.
+type ApplicationFilter struct {
+	Statuses      []ApplicationStatus
+	Roles         []JobRole
+	Company       string // Case-insensitive substring match.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	RemotePolicies  []RemotePolicy
+	EmploymentTypes []EmploymentType
+	Sources         []ApplicationSource
+	Location        string // Case-insensitive substring match.
+
+	// Applications without a salary never match these.
+	SalaryCurrency string  // Only salaries in this currency.
+	MinSalary      float64 // A yearly amount in SalaryCurrency. Only salaries whose annualised range reaches at least this much.
+
+	Tags         []string // Tag names, ignoring case.
+	MatchAllTags bool     // Require every tag in Tags rather than any of them.
+
+	Archived ArchivedFilter
+}
+
+// Reports whether `job_application` passes the filter. Used by FakeStore, DB filters in SQL.
+// Tags aren't checked, since applications don't carry them; see MatchesTags.
+func (filter ApplicationFilter) Matches(job_application *JobApplication) bool {
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, job_application.status) {
+		return false
+	}
+	if len(filter.Roles) > 0 && !slices.Contains(filter.Roles, job_application.role) {
+		return false
+	}
+	if filter.Company != "" && !strings.Contains(strings.ToLower(job_application.company), strings.ToLower(filter.Company)) {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && job_application.createdAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !job_application.createdAt.Before(filter.CreatedBefore) {
+		return false
+	}
+
+	if filter.Archived == NotArchived && !job_application.archivedAt.IsZero() {
+		return false
+	}
+	if filter.Archived == OnlyArchived && job_application.archivedAt.IsZero() {
+		return false
+	}
+
+	details := job_application.details
+	if len(filter.RemotePolicies) > 0 && !slices.Contains(filter.RemotePolicies, details.RemotePolicy) {
+		return false
+	}
+	if len(filter.EmploymentTypes) > 0 && !slices.Contains(filter.EmploymentTypes, details.EmploymentType) {
+		return false
+	}
+	if len(filter.Sources) > 0 && !slices.Contains(filter.Sources, details.Source) {
+		return false
+	}
+	if filter.Location != "" && !strings.Contains(strings.ToLower(details.Location), strings.ToLower(filter.Location)) {
+		return false
+	}
+	if filter.SalaryCurrency != "" && (details.Salary == nil || !strings.EqualFold(details.Salary.Currency, filter.SalaryCurrency)) {
+		return false
+	}
+	if filter.MinSalary > 0 && (details.Salary == nil || details.Salary.annualMax() < filter.MinSalary) {
+		return false
+	}
+
+	return true
+}
+
+// Reports whether an application with tags named `tag_names` passes the filter's tag conditions.
+func (filter ApplicationFilter) MatchesTags(tag_names []string) bool {
+	if len(filter.Tags) == 0 {
+		return true
+	}
+
+	has_tag := func(wanted string) bool {
+		return slices.ContainsFunc(tag_names, func(name string) bool { return strings.EqualFold(name, wanted) })
+	}
+
+	if filter.MatchAllTags {
+		return !slices.ContainsFunc(filter.Tags, func(wanted string) bool { return !has_tag(wanted) })
+	}
+
+	return slices.ContainsFunc(filter.Tags, has_tag)
+}
+
+// Builds the SQL conditions for the filter, appending to `args` and numbering placeholders after any already in it.
+// Returns conditions to be joined with "and".
+func (filter ApplicationFilter) sqlConditions(args []any) ([]string, []any) {
+	conditions := []string{}
+
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]int16, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = int16(status)
+		}
+		conditions = append(conditions, "status = any("+placeholder(statuses)+")")
+	}
+	if len(filter.Roles) > 0 {
+		conditions = append(conditions, "role = any("+placeholder(stringSlice(filter.Roles))+")")
+	}
+	if filter.Company != "" {
+		conditions = append(conditions, "strpos(lower(company), lower("+placeholder(filter.Company)+")) > 0")
+	}
+	if !filter.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at >= "+placeholder(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < "+placeholder(filter.CreatedBefore))
+	}
+	switch filter.Archived {
+	case NotArchived:
+		conditions = append(conditions, "archived_at is null")
+	case OnlyArchived:
+		conditions = append(conditions, "archived_at is not null")
+	}
+	if len(filter.RemotePolicies) > 0 {
+		conditions = append(conditions, "remote_policy = any("+placeholder(stringSlice(filter.RemotePolicies))+")")
+	}
+	if len(filter.EmploymentTypes) > 0 {
+		conditions = append(conditions, "employment_type = any("+placeholder(stringSlice(filter.EmploymentTypes))+")")
+	}
+	if len(filter.Sources) > 0 {
+		conditions = append(conditions, "source = any("+placeholder(stringSlice(filter.Sources))+")")
+	}
+	if filter.Location != "" {
+		conditions = append(conditions, "strpos(lower(location), lower("+placeholder(filter.Location)+")) > 0")
+	}
+	if filter.SalaryCurrency != "" {
+		conditions = append(conditions, "salary_currency = upper("+placeholder(filter.SalaryCurrency)+")")
+	}
+	if filter.MinSalary > 0 {
+		conditions = append(conditions, "salary_max * "+salaryPeriodsPerYearSQL()+" >= "+placeholder(filter.MinSalary))
+	}
+	if len(filter.Tags) > 0 {
+		tag_names := make([]string, len(filter.Tags))
+		for i, name := range filter.Tags {
+			tag_names[i] = strings.ToLower(name)
+		}
+		slices.Sort(tag_names)
+		tag_names = slices.Compact(tag_names)
+
+		matching_tags := `(select count(*) from application_tags join tags on tags.id = application_tags.tag_id
+			where application_tags.username = applications.username and application_tags.company = applications.company
+			and lower(tags.name) = any(` + placeholder(tag_names) + `))`
+		if filter.MatchAllTags {
+			conditions = append(conditions, matching_tags+" = "+strconv.Itoa(len(tag_names)))
+		} else {
+			conditions = append(conditions, matching_tags+" > 0")
+		}
+	}
+
+	return conditions, args
+}
+
+// A SQL expression for how many of the row's salary_period make up a year, from periodsPerYear.
+func salaryPeriodsPerYearSQL() string {
+	var expression strings.Builder
+
+	expression.WriteString("case salary_period")
+	for _, period := range GetSupportedPayPeriods() {
+		expression.WriteString(" when '" + string(period) + "' then " + strconv.FormatFloat(periodsPerYear[period], 'f', -1, 64))
+	}
+	expression.WriteString(" end")
+
+	return expression.String()
+}
+
+// Converts a slice of string-based enum values to []string, for SQL array parameters.
+func stringSlice[T ~string](values []T) []string {
+	converted := make([]string, len(values))
+	for i, value := range values {
+		converted[i] = string(value)
+	}
+	return converted
+}
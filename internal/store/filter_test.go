@@ -0,0 +1,91 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplicationFilterMatches(t *testing.T) {
+	job_application, err := NewJobApplication("Medidew Inc.", SoftwareEngineer, PendingResponse, []string{}, ApplicationDetails{
+		Salary:       &SalaryRange{Min: 70000, Max: 90000, Currency: "EUR"},
+		Location:     "Berlin, Germany",
+		RemotePolicy: Hybrid,
+		Source:       RecruiterOutreach,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+	job_application.SetTimestamps(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	cases := []struct {
+		name    string
+		filter  ApplicationFilter
+		matches bool
+	}{
+		{"empty", ApplicationFilter{}, true},
+		{"status", ApplicationFilter{Statuses: []ApplicationStatus{Active, PendingResponse}}, true},
+		{"other status", ApplicationFilter{Statuses: []ApplicationStatus{Rejected}}, false},
+		{"role", ApplicationFilter{Roles: []JobRole{SoftwareEngineer}}, true},
+		{"company substring", ApplicationFilter{Company: "medidew"}, true},
+		{"other company", ApplicationFilter{Company: "acme"}, false},
+		{"created after", ApplicationFilter{CreatedAfter: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{"created before", ApplicationFilter{CreatedBefore: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}, false},
+		{"remote policy", ApplicationFilter{RemotePolicies: []RemotePolicy{Hybrid, Remote}}, true},
+		{"other remote policy", ApplicationFilter{RemotePolicies: []RemotePolicy{Onsite}}, false},
+		{"employment type", ApplicationFilter{EmploymentTypes: []EmploymentType{Contract}}, false},
+		{"source", ApplicationFilter{Sources: []ApplicationSource{RecruiterOutreach}}, true},
+		{"location substring", ApplicationFilter{Location: "berlin"}, true},
+		{"salary currency", ApplicationFilter{SalaryCurrency: "eur"}, true},
+		{"salary reaches", ApplicationFilter{SalaryCurrency: "EUR", MinSalary: 90000}, true},
+		{"salary too low", ApplicationFilter{SalaryCurrency: "EUR", MinSalary: 90001}, false},
+		{"salary in another currency", ApplicationFilter{SalaryCurrency: "USD", MinSalary: 1}, false},
+		{"not archived", ApplicationFilter{Archived: NotArchived}, true},
+		{"only archived", ApplicationFilter{Archived: OnlyArchived}, false},
+	}
+
+	for _, test_case := range cases {
+		if test_case.filter.Matches(job_application) != test_case.matches {
+			t.Errorf("%s: expected Matches to be %v", test_case.name, test_case.matches)
+		}
+	}
+}
+
+func TestApplicationFilterSQLConditions(t *testing.T) {
+	filter := ApplicationFilter{
+		Statuses: []ApplicationStatus{Offer},
+		Company:  "medidew",
+	}
+
+	conditions, args := filter.sqlConditions([]any{"testuser"})
+
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got %d", len(args))
+	}
+	joined := strings.Join(conditions, " and ")
+	if joined != "status = any($2) and strpos(lower(company), lower($3)) > 0" {
+		t.Errorf("Unexpected conditions %q", joined)
+	}
+}
+
+func TestApplicationFilterAnnualisesMinSalary(t *testing.T) {
+	job_application, err := NewJobApplication("Medidew Inc.", SoftwareEngineer, PendingResponse, []string{}, ApplicationDetails{
+		Salary: &SalaryRange{Min: 5000, Max: 6000, Currency: "GBP", Period: PerMonth},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+
+	if !(ApplicationFilter{SalaryCurrency: "GBP", MinSalary: 72000}).Matches(job_application) {
+		t.Errorf("Expected 6000 a month to reach 72000 a year")
+	}
+	if (ApplicationFilter{SalaryCurrency: "GBP", MinSalary: 72001}).Matches(job_application) {
+		t.Errorf("Expected 6000 a month not to reach 72001 a year")
+	}
+
+	conditions, _ := ApplicationFilter{SalaryCurrency: "GBP", MinSalary: 72000}.sqlConditions(nil)
+	expected := "salary_max * case salary_period when 'year' then 1 when 'month' then 12 when 'week' then 52 when 'hour' then 2080 end >= $2"
+	if conditions[1] != expected {
+		t.Errorf("Expected condition %q, got %q", expected, conditions[1])
+	}
+}
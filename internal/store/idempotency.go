@@ -0,0 +1,90 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// The stored response to a request made with an idempotency key, replayed when the request is retried.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Claims `key` for a request hashing to `requestHash`, until `expiresAt`.
+// Returns nil if the caller should handle the request and then call CompleteIdempotentRequest or
+// ReleaseIdempotentRequest, or the stored response if the request was already handled.
+// Returns ErrIdempotencyMismatch or ErrIdempotencyInProgress if the key can't be used yet.
+func (db *DB) BeginIdempotentRequest(ctx context.Context, username string, key string, requestHash []byte, expiresAt time.Time) (*IdempotentResponse, error) {
+	// An expired key is free to reuse.
+	_, err := db.conn().Exec(ctx, "delete from idempotency_keys where username=$1 and key=$2 and expires_at <= now()", username, key)
+	if err != nil {
+		return nil, err
+	}
+
+	command_tag, err := db.conn().Exec(ctx, `insert into idempotency_keys (username, key, request_hash, expires_at) values ($1, $2, $3, $4)
+		on conflict do nothing`, username, key, requestHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if command_tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var stored_hash []byte
+	var status_code *int
+	response := &IdempotentResponse{}
+
+	err = db.conn().QueryRow(ctx, "select request_hash, status_code, content_type, body from idempotency_keys where username=$1 and key=$2", username, key).
+		Scan(&stored_hash, &status_code, &response.ContentType, &response.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released between the insert and the select, so the first request failed and this one may retry.
+		return nil, ErrIdempotencyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(stored_hash, requestHash) {
+		return nil, ErrIdempotencyMismatch
+	}
+	if status_code == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	response.StatusCode = *status_code
+	return response, nil
+}
+
+// Stores the response to the request that claimed `key`, to be replayed on retries.
+func (db *DB) CompleteIdempotentRequest(ctx context.Context, username string, key string, response *IdempotentResponse) error {
+	_, err := db.conn().Exec(ctx, "update idempotency_keys set status_code=$1, content_type=$2, body=$3 where username=$4 and key=$5",
+		response.StatusCode,
+		response.ContentType,
+		response.Body,
+		username,
+		key,
+	)
+	return err
+}
+
+// Frees `key` after the request that claimed it failed, so a retry runs it again.
+func (db *DB) ReleaseIdempotentRequest(ctx context.Context, username string, key string) error {
+	_, err := db.conn().Exec(ctx, "delete from idempotency_keys where username=$1 and key=$2 and status_code is null", username, key)
+	return err
+}
+
+// Deletes every user's keys that expired before `now`, returning how many.
+func (db *DB) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	command_tag, err := db.conn().Exec(ctx, "delete from idempotency_keys where expires_at <= $1", now)
+	if err != nil {
+		return 0, err
+	}
+
+	return command_tag.RowsAffected(), nil
+}
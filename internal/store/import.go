@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+)
+
+// Outcome of importing a single application.
+type ImportOutcome string
+
+const (
+	ImportCreated ImportOutcome = "created"
+	ImportSkipped ImportOutcome = "skipped" // An application for the company already exists.
+	ImportFailed  ImportOutcome = "failed"
+)
+
+// Inserts `applications` in a single transaction, skipping companies the user already has an application for.
+// Returns one outcome per application, in order. With `dryRun`, the transaction is rolled back so nothing is saved.
+func (db *DB) ImportApplications(ctx context.Context, username string, applications []*JobApplication, dryRun bool) ([]ImportOutcome, error) {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	outcomes := make([]ImportOutcome, 0, len(applications))
+
+	for _, application := range applications {
+		command_tag, err := tx.Exec(ctx, "insert into applications (company, role, status, notes, posting_url, username) values ($1, $2, $3, $4, $5, $6) on conflict (username, company) do nothing",
+			application.GetCompany(),
+			application.GetRole(),
+			application.GetStatus(),
+			application.GetNotes(),
+			application.GetPostingURL(),
+			username,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if command_tag.RowsAffected() == 0 {
+			outcomes = append(outcomes, ImportSkipped)
+		} else {
+			outcomes = append(outcomes, ImportCreated)
+		}
+	}
+
+	if dryRun {
+		return outcomes, nil
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return outcomes, nil
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Type for standardising kinds of interview.
+type InterviewType string
+
+const (
+	PhoneScreen          InterviewType = "phone_screen"
+	TechnicalInterview   InterviewType = "technical"
+	OnsiteInterview      InterviewType = "onsite"
+	BehaviouralInterview InterviewType = "behavioural"
+)
+
+func GetSupportedInterviewTypes() []InterviewType {
+	return []InterviewType{PhoneScreen, TechnicalInterview, OnsiteInterview, BehaviouralInterview}
+}
+
+// Type for standardising how an interview went.
+type InterviewOutcome string
+
+const (
+	InterviewPending   InterviewOutcome = "pending"
+	InterviewPassed    InterviewOutcome = "passed"
+	InterviewFailed    InterviewOutcome = "failed"
+	InterviewCancelled InterviewOutcome = "cancelled"
+)
+
+func GetSupportedInterviewOutcomes() []InterviewOutcome {
+	return []InterviewOutcome{InterviewPending, InterviewPassed, InterviewFailed, InterviewCancelled}
+}
+
+const (
+	defaultInterviewMinutes int = 60
+	maxInterviewMinutes     int = 24 * 60
+)
+
+// A scheduled interview for an application.
+type Interview struct {
+	ID              int64            `json:"id"`
+	Company         string           `json:"company"` // The application the interview is for.
+	Type            InterviewType    `json:"type"`
+	ScheduledAt     time.Time        `json:"scheduled_at"` // In Timezone, once validated or loaded.
+	Timezone        string           `json:"timezone"`     // IANA name, e.g. "Europe/London".
+	DurationMinutes int              `json:"duration_minutes"`
+	Location        string           `json:"location"`
+	VideoLink       string           `json:"video_link"`
+	Interviewers    []string         `json:"interviewers"`
+	Outcome         InterviewOutcome `json:"outcome"`
+	Feedback        string           `json:"feedback"`
+}
+
+// Checks the interview's fields, filling in defaults and converting ScheduledAt to its timezone.
+func (interview *Interview) Validate() error {
+	if !slices.Contains(GetSupportedInterviewTypes(), interview.Type) {
+		return errors.New("`type` is not supported by type InterviewType")
+	}
+
+	if interview.ScheduledAt.IsZero() {
+		return errors.New("`scheduled_at` is required")
+	}
+
+	if interview.Timezone == "" {
+		interview.Timezone = "UTC"
+	}
+	err := interview.localise()
+	if err != nil {
+		return errors.New("`timezone` is not a known IANA time zone")
+	}
+
+	if interview.DurationMinutes == 0 {
+		interview.DurationMinutes = defaultInterviewMinutes
+	}
+	if interview.DurationMinutes < 0 || interview.DurationMinutes > maxInterviewMinutes {
+		return errors.New("`duration_minutes` must be between 1 and " + strconv.Itoa(maxInterviewMinutes))
+	}
+
+	if interview.VideoLink != "" {
+		parsed_url, err := url.Parse(interview.VideoLink)
+		if err != nil || (parsed_url.Scheme != "https" && parsed_url.Scheme != "http") || parsed_url.Host == "" {
+			return errors.New("`video_link` must be an http or https URL")
+		}
+	}
+
+	if interview.Interviewers == nil {
+		interview.Interviewers = []string{}
+	}
+	for i, interviewer := range interview.Interviewers {
+		interview.Interviewers[i] = strings.TrimSpace(interviewer)
+	}
+
+	if interview.Outcome == "" {
+		interview.Outcome = InterviewPending
+	}
+	if !slices.Contains(GetSupportedInterviewOutcomes(), interview.Outcome) {
+		return errors.New("`outcome` is not supported by type InterviewOutcome")
+	}
+
+	return nil
+}
+
+// Returns when the interview is due to finish.
+func (interview *Interview) EndsAt() time.Time {
+	return interview.ScheduledAt.Add(time.Duration(interview.DurationMinutes) * time.Minute)
+}
+
+func (interview *Interview) localise() error {
+	location, err := time.LoadLocation(interview.Timezone)
+	if err != nil {
+		return err
+	}
+
+	interview.ScheduledAt = interview.ScheduledAt.In(location)
+	return nil
+}
+
+// Narrows which interviews are listed. Zero values match everything.
+type InterviewFilter struct {
+	Company        string    // Only interviews for this application.
+	ScheduledAfter time.Time // Only interviews starting at or after this time, soonest first.
+	Outcome        InterviewOutcome
+	Limit          int // 0 for no limit.
+}
+
+func (filter InterviewFilter) Matches(interview *Interview) bool {
+	if filter.Company != "" && interview.Company != filter.Company {
+		return false
+	}
+	if !filter.ScheduledAfter.IsZero() && interview.ScheduledAt.Before(filter.ScheduledAfter) {
+		return false
+	}
+	if filter.Outcome != "" && interview.Outcome != filter.Outcome {
+		return false
+	}
+	return true
+}
+
+const interviewColumns string = "id, company, type, scheduled_at, timezone, duration_minutes, location, video_link, interviewers, outcome, feedback"
+
+func scanInterview(row pgx.Row) (*Interview, error) {
+	interview := &Interview{}
+
+	err := row.Scan(
+		&interview.ID,
+		&interview.Company,
+		&interview.Type,
+		&interview.ScheduledAt,
+		&interview.Timezone,
+		&interview.DurationMinutes,
+		&interview.Location,
+		&interview.VideoLink,
+		&interview.Interviewers,
+		&interview.Outcome,
+		&interview.Feedback,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored zones were validated on the way in, but fall back to UTC rather than fail a read.
+	if interview.localise() != nil {
+		interview.ScheduledAt = interview.ScheduledAt.UTC()
+	}
+
+	return interview, nil
+}
+
+// Lists interviews ordered by when they're scheduled.
+func (db *DB) ListInterviews(ctx context.Context, username string, filter InterviewFilter) ([]*Interview, error) {
+	// Interviews for trashed applications are hidden along with them.
+	conditions := []string{"username=$1", notTrashed("interviews")}
+	args := []any{username}
+
+	if filter.Company != "" {
+		args = append(args, filter.Company)
+		conditions = append(conditions, "company=$"+strconv.Itoa(len(args)))
+	}
+	if !filter.ScheduledAfter.IsZero() {
+		args = append(args, filter.ScheduledAfter)
+		conditions = append(conditions, "scheduled_at >= $"+strconv.Itoa(len(args)))
+	}
+	if filter.Outcome != "" {
+		args = append(args, filter.Outcome)
+		conditions = append(conditions, "outcome=$"+strconv.Itoa(len(args)))
+	}
+
+	sql := "select " + interviewColumns + " from interviews where " + strings.Join(conditions, " and ") + " order by scheduled_at, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sql += " limit $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := db.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	interviews := []*Interview{}
+
+	for rows.Next() {
+		interview, err := scanInterview(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, interview)
+	}
+
+	return interviews, rows.Err()
+}
+
+func (db *DB) GetInterview(ctx context.Context, username string, companyID string, interviewID int64) (*Interview, error) {
+	return scanInterview(db.conn().QueryRow(ctx, "select "+interviewColumns+" from interviews where id=$1 and username=$2 and company=$3 and "+notTrashed("interviews"), interviewID, username, companyID))
+}
+
+// Saves a new interview for interview.Company, setting its ID.
+// With `advanceStatus`, an application pending a response becomes Active, as the employer has replied.
+func (db *DB) CreateInterview(ctx context.Context, username string, interview *Interview, advanceStatus bool) error {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	// Selected through the live application, so a trashed one can't gain interviews.
+	err = tx.QueryRow(ctx, `insert into interviews (username, company, type, scheduled_at, timezone, duration_minutes, location, video_link, interviewers, outcome, feedback)
+		select username, company, $3, $4, $5, $6, $7, $8, $9, $10, $11 from applications where username=$1 and company=$2 and deleted_at is null
+		returning id`,
+		username,
+		interview.Company,
+		interview.Type,
+		interview.ScheduledAt,
+		interview.Timezone,
+		interview.DurationMinutes,
+		interview.Location,
+		interview.VideoLink,
+		interview.Interviewers,
+		interview.Outcome,
+		interview.Feedback,
+	).Scan(&interview.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if advanceStatus {
+		_, err = tx.Exec(ctx, "update applications set status=$1, responded_at=coalesce(responded_at, now()), status_changed_at=now(), updated_at=now(), version=version+1 where username=$2 and company=$3 and status=$4 and deleted_at is null",
+			Active,
+			username,
+			interview.Company,
+			PendingResponse,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Replaces every field of the interview with ID interview.ID, such as to record its outcome.
+func (db *DB) UpdateInterview(ctx context.Context, username string, interview *Interview) error {
+	command_tag, err := db.conn().Exec(ctx, `update interviews set type=$1, scheduled_at=$2, timezone=$3, duration_minutes=$4, location=$5, video_link=$6, interviewers=$7, outcome=$8, feedback=$9
+		where id=$10 and username=$11 and company=$12 and `+notTrashed("interviews"),
+		interview.Type,
+		interview.ScheduledAt,
+		interview.Timezone,
+		interview.DurationMinutes,
+		interview.Location,
+		interview.VideoLink,
+		interview.Interviewers,
+		interview.Outcome,
+		interview.Feedback,
+		interview.ID,
+		username,
+		interview.Company,
+	)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteInterview(ctx context.Context, username string, companyID string, interviewID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from interviews where id=$1 and username=$2 and company=$3 and "+notTrashed("interviews"), interviewID, username, companyID)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
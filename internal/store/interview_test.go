@@ -0,0 +1,47 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterviewValidate(t *testing.T) {
+	interview := &Interview{
+		Type:        TechnicalInterview,
+		ScheduledAt: time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC),
+		Timezone:    "America/New_York",
+	}
+
+	err := interview.Validate()
+	if err != nil {
+		t.Fatalf("Expected a valid interview, got %v", err)
+	}
+	if interview.DurationMinutes != defaultInterviewMinutes || interview.Outcome != InterviewPending {
+		t.Errorf("Expected defaults to be filled in, got %+v", interview)
+	}
+	if interview.ScheduledAt.Hour() != 10 {
+		t.Errorf("Expected the time in New York, got %v", interview.ScheduledAt)
+	}
+	if !interview.EndsAt().Equal(time.Date(2025, 6, 2, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected end time %v", interview.EndsAt())
+	}
+}
+
+func TestInterviewValidateInvalid(t *testing.T) {
+	scheduled_at := time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC)
+
+	cases := map[string]Interview{
+		"bad type":     {Type: "coffee", ScheduledAt: scheduled_at},
+		"no time":      {Type: PhoneScreen},
+		"bad timezone": {Type: PhoneScreen, ScheduledAt: scheduled_at, Timezone: "Mars/Olympus_Mons"},
+		"too long":     {Type: PhoneScreen, ScheduledAt: scheduled_at, DurationMinutes: 2 * maxInterviewMinutes},
+		"bad link":     {Type: PhoneScreen, ScheduledAt: scheduled_at, VideoLink: "zoom meeting 123"},
+		"bad outcome":  {Type: PhoneScreen, ScheduledAt: scheduled_at, Outcome: "ghosted"},
+	}
+
+	for name, interview := range cases {
+		if interview.Validate() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -3,7 +3,11 @@ package store
 import (
 	"encoding/json"
 	"errors"
+	"net/url"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Type for standardising application status values.
@@ -33,6 +37,24 @@ func (jr ApplicationStatus) String() string {
 	}
 }
 
+// Parses a status from its String() name (case-insensitive) or its numeric value.
+func ParseApplicationStatus(value string) (ApplicationStatus, error) {
+	value = strings.TrimSpace(value)
+
+	for status := Active; status <= MaxStatus; status++ {
+		if strings.EqualFold(value, status.String()) {
+			return status, nil
+		}
+	}
+
+	number, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || ApplicationStatus(number) > MaxStatus {
+		return 0, errors.New("`status` is not supported by type ApplicationStatus")
+	}
+
+	return ApplicationStatus(number), nil
+}
+
 // Type for standardising jobs title strings.
 type JobRole string
 
@@ -50,6 +72,24 @@ type JobApplication struct {
 	role    JobRole
 	status  ApplicationStatus
 	notes   []string
+
+	createdAt       time.Time
+	updatedAt       time.Time
+	followUpAt      time.Time // Zero when no follow-up is scheduled.
+	respondedAt     time.Time // When the employer first replied, zero until they do.
+	statusChangedAt time.Time // When the status was last set, for the auto-archive rules.
+	archivedAt      time.Time // When it was archived, zero while it's in the active pipeline.
+	unarchivedAt    time.Time // When it was last unarchived, which restarts ArchiveRule clocks.
+	deletedAt       time.Time // When it was moved to the trash, zero otherwise.
+	version         int64     // Bumped by every update, sent as the ETag.
+
+	postingURL string // Link to the job advert, empty if unknown.
+	details    ApplicationDetails
+
+	customFields map[string]any // Values of the user's custom fields by name, checked by ValidateCustomFieldValues.
+
+	contacts []*Contact // Only loaded for the detail view, nil otherwise.
+	tags     []*Tag     // Likewise.
 }
 
 func (job_application *JobApplication) MarshalJSON() ([]byte, error) {
@@ -69,6 +109,62 @@ func (job_application *JobApplication) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Join(errors.New("could not marshal notes"), err)
 	}
+	created_at_json, err := marshalOptionalTime(job_application.createdAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal created_at"), err)
+	}
+	updated_at_json, err := marshalOptionalTime(job_application.updatedAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal updated_at"), err)
+	}
+	follow_up_at_json, err := marshalOptionalTime(job_application.followUpAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal follow_up_at"), err)
+	}
+	responded_at_json, err := marshalOptionalTime(job_application.respondedAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal responded_at"), err)
+	}
+	status_changed_at_json, err := marshalOptionalTime(job_application.statusChangedAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal status_changed_at"), err)
+	}
+	archived_at_json, err := marshalOptionalTime(job_application.archivedAt)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal archived_at"), err)
+	}
+	posting_url_json, err := marshalOptionalString(job_application.postingURL)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal posting_url"), err)
+	}
+	salary_json, err := json.Marshal(job_application.details.Salary)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal salary"), err)
+	}
+	location_json, err := marshalOptionalString(job_application.details.Location)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal location"), err)
+	}
+	remote_policy_json, err := marshalOptionalString(string(job_application.details.RemotePolicy))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal remote_policy"), err)
+	}
+	employment_type_json, err := marshalOptionalString(string(job_application.details.EmploymentType))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal employment_type"), err)
+	}
+	source_json, err := marshalOptionalString(string(job_application.details.Source))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal source"), err)
+	}
+	version_json, err := json.Marshal(job_application.version)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal version"), err)
+	}
+	custom_fields_json, err := json.Marshal(nonNilValues(job_application.customFields))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not marshal custom_fields"), err)
+	}
 
 	// I could construct this as a string then convert afterwards to make it cleaner,
 	//	but this version is impervious to whether I change JobApplication's field types,
@@ -82,6 +178,58 @@ func (job_application *JobApplication) MarshalJSON() ([]byte, error) {
 	result = append(result, status_json...)
 	result = append(result, []byte(`, "notes":`)...)
 	result = append(result, notes_json...)
+	result = append(result, []byte(`, "created_at":`)...)
+	result = append(result, created_at_json...)
+	result = append(result, []byte(`, "updated_at":`)...)
+	result = append(result, updated_at_json...)
+	result = append(result, []byte(`, "follow_up_at":`)...)
+	result = append(result, follow_up_at_json...)
+	result = append(result, []byte(`, "responded_at":`)...)
+	result = append(result, responded_at_json...)
+	result = append(result, []byte(`, "status_changed_at":`)...)
+	result = append(result, status_changed_at_json...)
+	result = append(result, []byte(`, "archived_at":`)...)
+	result = append(result, archived_at_json...)
+	result = append(result, []byte(`, "posting_url":`)...)
+	result = append(result, posting_url_json...)
+	result = append(result, []byte(`, "salary":`)...)
+	result = append(result, salary_json...)
+	result = append(result, []byte(`, "location":`)...)
+	result = append(result, location_json...)
+	result = append(result, []byte(`, "remote_policy":`)...)
+	result = append(result, remote_policy_json...)
+	result = append(result, []byte(`, "employment_type":`)...)
+	result = append(result, employment_type_json...)
+	result = append(result, []byte(`, "source":`)...)
+	result = append(result, source_json...)
+	result = append(result, []byte(`, "custom_fields":`)...)
+	result = append(result, custom_fields_json...)
+	result = append(result, []byte(`, "version":`)...)
+	result = append(result, version_json...)
+	if !job_application.deletedAt.IsZero() {
+		deleted_at_json, err := marshalOptionalTime(job_application.deletedAt)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not marshal deleted_at"), err)
+		}
+		result = append(result, []byte(`, "deleted_at":`)...)
+		result = append(result, deleted_at_json...)
+	}
+	if job_application.contacts != nil {
+		contacts_json, err := json.Marshal(job_application.contacts)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not marshal contacts"), err)
+		}
+		result = append(result, []byte(`, "contacts":`)...)
+		result = append(result, contacts_json...)
+	}
+	if job_application.tags != nil {
+		tags_json, err := json.Marshal(job_application.tags)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not marshal tags"), err)
+		}
+		result = append(result, []byte(`, "tags":`)...)
+		result = append(result, tags_json...)
+	}
 	result = append(result, []byte(`}`)...)
 
 	return result, nil
@@ -92,12 +240,27 @@ func (JobApplication *JobApplication) UnmarshalJSON(data []byte) error {
 		Company string            `json:"company"`
 		Role    JobRole           `json:"role"`
 		Status  ApplicationStatus `json:"status"`
+
+		FollowUpAt *time.Time `json:"follow_up_at"`
+		PostingURL string     `json:"posting_url"`
+
+		Salary         *SalaryRange      `json:"salary"`
+		Location       string            `json:"location"`
+		RemotePolicy   RemotePolicy      `json:"remote_policy"`
+		EmploymentType EmploymentType    `json:"employment_type"`
+		Source         ApplicationSource `json:"source"`
+
+		CustomFields map[string]any `json:"custom_fields"`
 	}{}
 
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
 
+	if strings.TrimSpace(aux.Company) == "" {
+		return errors.New("`company` is required")
+	}
+
 	if !slices.Contains(GetSupportedJobRoles(), aux.Role) {
 		return errors.New("`role` is not supported by type JobRole")
 	}
@@ -106,14 +269,57 @@ func (JobApplication *JobApplication) UnmarshalJSON(data []byte) error {
 		return errors.New("`status` is not supported by type ApplicationStatus")
 	}
 
+	if err := ValidatePostingURL(aux.PostingURL); err != nil {
+		return err
+	}
+
+	details := ApplicationDetails{
+		Salary:         aux.Salary,
+		Location:       aux.Location,
+		RemotePolicy:   aux.RemotePolicy,
+		EmploymentType: aux.EmploymentType,
+		Source:         aux.Source,
+	}
+	if err := details.Validate(); err != nil {
+		return err
+	}
+
 	JobApplication.company = aux.Company
 	JobApplication.role = aux.Role
 	JobApplication.status = aux.Status
 
+	if aux.FollowUpAt != nil {
+		JobApplication.followUpAt = *aux.FollowUpAt
+	}
+	JobApplication.postingURL = aux.PostingURL
+	JobApplication.details = details
+	JobApplication.customFields = aux.CustomFields
+
 	return nil
 }
 
-func NewJobApplication(company string, role JobRole, status ApplicationStatus, notes []string) (*JobApplication, error) {
+// Marshals zero times as null, so unset dates don't show up as year 1.
+func marshalOptionalTime(value time.Time) ([]byte, error) {
+	if value.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(value.UTC())
+}
+
+// Marshals empty strings as null, like unset times.
+func marshalOptionalString(value string) ([]byte, error) {
+	if value == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(value)
+}
+
+// Creates a validated application. `details` is optional, and at most one may be given.
+func NewJobApplication(company string, role JobRole, status ApplicationStatus, notes []string, details ...ApplicationDetails) (*JobApplication, error) {
+	if strings.TrimSpace(company) == "" {
+		return nil, errors.New("`company` is required")
+	}
+
 	if !slices.Contains(GetSupportedJobRoles(), role) {
 		return nil, errors.New("`role` is not supported by type JobRole")
 	}
@@ -122,12 +328,25 @@ func NewJobApplication(company string, role JobRole, status ApplicationStatus, n
 		return nil, errors.New("`status` is not supported by type ApplicationStatus")
 	}
 
-	return &JobApplication{
+	job_application := &JobApplication{
 		company: company,
 		role:    role,
 		status:  status,
 		notes:   notes,
-	}, nil
+		version: 1,
+	}
+
+	if len(details) > 1 {
+		return nil, errors.New("at most one ApplicationDetails may be given")
+	}
+	if len(details) == 1 {
+		err := job_application.SetDetails(details[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return job_application, nil
 }
 
 func (job_application *JobApplication) GetCompany() string {
@@ -147,11 +366,174 @@ func (job_application *JobApplication) UpdateStatus(status ApplicationStatus) er
 		return errors.New("`status` is not supported by type ApplicationStatus")
 	}
 
+	if job_application.respondedAt.IsZero() && IsEmployerResponse(job_application.status, status) {
+		job_application.respondedAt = time.Now()
+	}
+	if status != job_application.status {
+		job_application.statusChangedAt = time.Now()
+	}
+
 	job_application.status = status
 
 	return nil
 }
 
+// Reports whether moving from status `from` to `to` means the employer replied:
+// a rejection or offer, or the ball coming back to the applicant while awaiting a response.
+func IsEmployerResponse(from ApplicationStatus, to ApplicationStatus) bool {
+	return to == Rejected || to == Offer || (from == PendingResponse && to == Active)
+}
+
+// Returns when the employer first replied, or the zero time if they haven't.
+func (job_application *JobApplication) GetRespondedAt() time.Time {
+	return job_application.respondedAt
+}
+
+// Sets when the employer first replied. Only stores should call this.
+func (job_application *JobApplication) SetRespondedAt(respondedAt time.Time) {
+	job_application.respondedAt = respondedAt
+}
+
+func (job_application *JobApplication) GetCreatedAt() time.Time {
+	return job_application.createdAt
+}
+
+func (job_application *JobApplication) GetUpdatedAt() time.Time {
+	return job_application.updatedAt
+}
+
+// Sets when the application was first saved and last changed. Only stores should call this.
+func (job_application *JobApplication) SetTimestamps(createdAt time.Time, updatedAt time.Time) {
+	job_application.createdAt = createdAt
+	job_application.updatedAt = updatedAt
+}
+
+// Returns when the status was last set, or the zero time if it hasn't been saved.
+func (job_application *JobApplication) GetStatusChangedAt() time.Time {
+	return job_application.statusChangedAt
+}
+
+// Sets when the status was last set. Only stores should call this.
+func (job_application *JobApplication) SetStatusChangedAt(statusChangedAt time.Time) {
+	job_application.statusChangedAt = statusChangedAt
+}
+
+// Returns when the application was archived, or the zero time if it isn't.
+func (job_application *JobApplication) GetArchivedAt() time.Time {
+	return job_application.archivedAt
+}
+
+// Sets when the application was archived. The zero time unarchives it. Only stores should call this.
+func (job_application *JobApplication) SetArchivedAt(archivedAt time.Time) {
+	job_application.archivedAt = archivedAt
+}
+
+// Returns the application's version, which every update bumps.
+func (job_application *JobApplication) GetVersion() int64 {
+	return job_application.version
+}
+
+// Sets the application's version. Only stores should call this.
+func (job_application *JobApplication) SetVersion(version int64) {
+	job_application.version = version
+}
+
+// Returns when the application was moved to the trash, or the zero time if it's live.
+func (job_application *JobApplication) GetDeletedAt() time.Time {
+	return job_application.deletedAt
+}
+
+// Sets when the application was moved to the trash. Only stores should call this.
+func (job_application *JobApplication) SetDeletedAt(deletedAt time.Time) {
+	job_application.deletedAt = deletedAt
+}
+
+// Returns when to next chase the employer, or the zero time if no follow-up is scheduled.
+func (job_application *JobApplication) GetFollowUpAt() time.Time {
+	return job_application.followUpAt
+}
+
+// Schedules a follow-up. The zero time clears it.
+func (job_application *JobApplication) SetFollowUpAt(followUpAt time.Time) {
+	job_application.followUpAt = followUpAt
+}
+
+// Returns the link to the job advert, or "" if there isn't one.
+func (job_application *JobApplication) GetPostingURL() string {
+	return job_application.postingURL
+}
+
+// Sets the link to the job advert. "" clears it.
+func (job_application *JobApplication) SetPostingURL(postingURL string) error {
+	err := ValidatePostingURL(postingURL)
+	if err != nil {
+		return err
+	}
+
+	job_application.postingURL = postingURL
+	return nil
+}
+
+// Accepts "" or an absolute http(s) URL.
+func ValidatePostingURL(postingURL string) error {
+	if postingURL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(postingURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("`posting_url` must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+// Returns the salary, location, remote policy, employment type and source.
+func (job_application *JobApplication) GetDetails() ApplicationDetails {
+	return job_application.details
+}
+
+// Validates and replaces the application's details.
+func (job_application *JobApplication) SetDetails(details ApplicationDetails) error {
+	err := details.Validate()
+	if err != nil {
+		return err
+	}
+
+	job_application.details = details
+	return nil
+}
+
+// Returns the custom field values by field name. May be nil when there are none.
+func (job_application *JobApplication) GetCustomFields() map[string]any {
+	return job_application.customFields
+}
+
+// Replaces the custom field values. Validate them with ValidateCustomFieldValues first.
+func (job_application *JobApplication) SetCustomFields(values map[string]any) {
+	job_application.customFields = values
+}
+
+// Returns the linked contacts, or nil if they weren't loaded.
+func (job_application *JobApplication) GetContacts() []*Contact {
+	return job_application.contacts
+}
+
+// Attaches linked contacts, so they're included when marshalled.
+func (job_application *JobApplication) SetContacts(contacts []*Contact) {
+	job_application.contacts = contacts
+}
+
+// Returns the application's tags, or nil if they weren't loaded.
+func (job_application *JobApplication) GetTags() []*Tag {
+	return job_application.tags
+}
+
+// Attaches the application's tags, so they're included when marshalled.
+func (job_application *JobApplication) SetTags(tags []*Tag) {
+	job_application.tags = tags
+}
+
 func (job_application *JobApplication) GetNotes() []string {
 	return job_application.notes
 }
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -117,3 +118,117 @@ func TestRemoveNotes(t *testing.T) {
 		t.Fatalf("Failed to remove notes correctly: %v", job_application)
 	}
 }
+
+func TestJobApplicationNewEmptyCompany(t *testing.T) {
+	_, err := NewJobApplication("  ", SoftwareEngineer, Active, []string{})
+	if err == nil {
+		t.Fatalf("Failed to throw error on empty company")
+	}
+}
+
+func TestParseApplicationStatus(t *testing.T) {
+	valid := map[string]ApplicationStatus{
+		"Active":           Active,
+		"pending response": PendingResponse,
+		" REJECTED ":       Rejected,
+		"3":                Offer,
+	}
+
+	for value, expected := range valid {
+		status, err := ParseApplicationStatus(value)
+		if err != nil {
+			t.Errorf("ParseApplicationStatus(%q) error: %v", value, err)
+		} else if status != expected {
+			t.Errorf("ParseApplicationStatus(%q) = %v, expected %v", value, status, expected)
+		}
+	}
+
+	for _, value := range []string{"", "Ghosted", "4", "-1"} {
+		_, err := ParseApplicationStatus(value)
+		if err == nil {
+			t.Errorf("Failed to throw error on invalid status %q", value)
+		}
+	}
+}
+
+func TestJobApplicationUpdateStatusRecordsResponse(t *testing.T) {
+	job_application, err := NewJobApplication("Medidew Inc.", SoftwareEngineer, Active, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+
+	_ = job_application.UpdateStatus(PendingResponse)
+	if !job_application.GetRespondedAt().IsZero() {
+		t.Fatalf("Applying is not a response from the employer")
+	}
+
+	_ = job_application.UpdateStatus(Active)
+	responded_at := job_application.GetRespondedAt()
+	if responded_at.IsZero() {
+		t.Fatalf("Expected moving from Pending Response to Active to record a response")
+	}
+
+	_ = job_application.UpdateStatus(Rejected)
+	if !job_application.GetRespondedAt().Equal(responded_at) {
+		t.Fatalf("Expected the first response time to be kept")
+	}
+}
+
+func TestJobApplicationPostingURL(t *testing.T) {
+	job_application := &JobApplication{}
+
+	err := job_application.UnmarshalJSON([]byte(`{"company": "Medidew Inc.", "role": "Software Engineer", "posting_url": "https://jobs.example.com/123"}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() error: %v", err)
+	}
+	if job_application.GetPostingURL() != "https://jobs.example.com/123" {
+		t.Errorf("Expected posting_url to be read, got %q", job_application.GetPostingURL())
+	}
+
+	for _, invalid := range []string{"jobs.example.com/123", "javascript:alert(1)", "ftp://example.com/job"} {
+		if job_application.SetPostingURL(invalid) == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	application_json, err := job_application.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error: %v", err)
+	}
+	if !strings.Contains(string(application_json), `"posting_url":"https://jobs.example.com/123"`) {
+		t.Errorf("Expected posting_url in %s", application_json)
+	}
+}
+
+func TestJobApplicationNewDetails(t *testing.T) {
+	details := ApplicationDetails{
+		Salary:       &SalaryRange{Min: 100, Max: 200, Currency: "eur"},
+		RemotePolicy: Remote,
+		Source:       JobBoard,
+	}
+
+	job_application, err := NewJobApplication("Medidew Inc.", SoftwareEngineer, Active, []string{}, details)
+	if err != nil {
+		t.Fatalf("Failed to create NewJobApplication: %v", err)
+	}
+
+	salary := job_application.GetDetails().Salary
+	if salary.Currency != "EUR" || salary.Period != PerYear {
+		t.Errorf("Expected the salary to be normalised, got %+v", salary)
+	}
+
+	invalid := []ApplicationDetails{
+		{Salary: &SalaryRange{Min: 100, Max: 200, Currency: "euros"}},
+		{Salary: &SalaryRange{Min: -1, Max: 200, Currency: "EUR"}},
+		{Salary: &SalaryRange{Min: 100, Max: 200, Currency: "EUR", Period: "fortnight"}},
+		{RemotePolicy: "anywhere"},
+		{EmploymentType: "gig"},
+		{Source: "billboard"},
+	}
+	for _, details := range invalid {
+		_, err = NewJobApplication("Medidew Inc.", SoftwareEngineer, Active, []string{}, details)
+		if err == nil {
+			t.Errorf("Expected %+v to be rejected", details)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Arbitrary key for pg_advisory_xact_lock, stops two instances migrating at once.
+const migrationLockID int64 = 7_514_283_001
+
+// Returns the embedded migration versions (file names without `.sql`) in the order they apply.
+func migrationVersions() ([]string, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []string{}
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimSuffix(entry.Name(), ".sql"))
+	}
+	slices.Sort(versions)
+
+	return versions, nil
+}
+
+// Returns the versions recorded in schema_migrations, or none if Migrate hasn't created it yet.
+// Only reads, as /readyz calls it on every probe.
+func (db *DB) appliedMigrations(ctx context.Context) ([]string, error) {
+	var exists bool
+	err := db.Pool.QueryRow(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []string{}, nil
+	}
+
+	rows, err := db.Pool.Query(ctx, "select version from schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := []string{}
+	for rows.Next() {
+		var version string
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, version)
+	}
+
+	return applied, rows.Err()
+}
+
+// Returns the embedded migrations that haven't been applied to the database yet.
+func (db *DB) PendingMigrations(ctx context.Context) ([]string, error) {
+	versions, err := migrationVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+	for _, version := range versions {
+		if !slices.Contains(applied, version) {
+			pending = append(pending, version)
+		}
+	}
+
+	return pending, nil
+}
+
+// Applies every pending migration, each in its own transaction. Returns the versions applied.
+func (db *DB) Migrate(ctx context.Context) ([]string, error) {
+	_, err := db.Pool.Exec(ctx, "create table if not exists schema_migrations (version text primary key, applied_at timestamptz not null default now())")
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []string{}
+	for _, version := range pending {
+		migration, err := migrationFiles.ReadFile("migrations/" + version + ".sql")
+		if err != nil {
+			return applied, err
+		}
+
+		tx, err := db.Pool.Begin(ctx)
+		if err != nil {
+			return applied, err
+		}
+
+		_, err = tx.Exec(ctx, "select pg_advisory_xact_lock($1)", migrationLockID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return applied, err
+		}
+
+		// Another instance may have applied it while we waited on the lock.
+		var already_applied bool
+		err = tx.QueryRow(ctx, "select exists(select 1 from schema_migrations where version=$1)", version).Scan(&already_applied)
+		if err != nil {
+			tx.Rollback(ctx)
+			return applied, err
+		}
+		if already_applied {
+			tx.Rollback(ctx)
+			continue
+		}
+
+		_, err = tx.Exec(ctx, string(migration))
+		if err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("migration %s failed: %w", version, err)
+		}
+
+		_, err = tx.Exec(ctx, "insert into schema_migrations (version) values ($1)", version)
+		if err != nil {
+			tx.Rollback(ctx)
+			return applied, err
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, version)
+	}
+
+	return applied, nil
+}
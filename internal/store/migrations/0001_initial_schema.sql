@@ -0,0 +1,18 @@
+create table if not exists users (
+    username        text primary key,
+    email           text not null unique,
+    argon2_memory   integer not null,
+    argon2_time     integer not null,
+    argon2_threads  integer not null,
+    hashed_password text not null,
+    salt            text not null
+);
+
+create table if not exists applications (
+    username text not null references users (username) on delete cascade,
+    company  text not null,
+    role     text not null,
+    status   smallint not null default 0,
+    notes    text[] not null default '{}',
+    primary key (username, company)
+);
@@ -0,0 +1,6 @@
+alter table applications
+    add column if not exists created_at   timestamptz not null default now(),
+    add column if not exists updated_at   timestamptz not null default now(),
+    add column if not exists follow_up_at timestamptz;
+
+create index if not exists applications_username_created_at_idx on applications (username, created_at);
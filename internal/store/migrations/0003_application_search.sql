@@ -0,0 +1,13 @@
+-- array_to_string is only stable, so generated columns can't call it directly.
+create or replace function applications_notes_text(notes text[]) returns text
+    language sql immutable parallel safe
+    as $$ select array_to_string(notes, ' ') $$;
+
+alter table applications
+    add column if not exists search_vector tsvector generated always as (
+        setweight(to_tsvector('english', company), 'A') ||
+        setweight(to_tsvector('english', role), 'B') ||
+        setweight(to_tsvector('english', applications_notes_text(notes)), 'C')
+    ) stored;
+
+create index if not exists applications_search_vector_idx on applications using gin (search_vector);
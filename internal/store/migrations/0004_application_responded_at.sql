@@ -0,0 +1,4 @@
+alter table applications add column if not exists responded_at timestamptz;
+
+-- Best guess for existing rejections and offers: the reply came with their last change.
+update applications set responded_at = updated_at where responded_at is null and status in (2, 3);
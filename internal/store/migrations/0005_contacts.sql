@@ -0,0 +1,23 @@
+create table if not exists contacts (
+    id           bigint generated always as identity primary key,
+    username     text not null references users (username) on delete cascade,
+    name         text not null,
+    email        text not null default '',
+    phone        text not null default '',
+    linkedin_url text not null default '',
+    company      text not null default '',
+    role         text not null default 'other',
+    created_at   timestamptz not null default now()
+);
+
+create index if not exists contacts_username_idx on contacts (username);
+
+create table if not exists application_contacts (
+    username   text not null,
+    company    text not null,
+    contact_id bigint not null references contacts (id) on delete cascade,
+    primary key (username, company, contact_id),
+    foreign key (username, company) references applications (username, company) on delete cascade
+);
+
+create index if not exists application_contacts_contact_id_idx on application_contacts (contact_id);
@@ -0,0 +1,18 @@
+create table if not exists interviews (
+    id               bigint generated always as identity primary key,
+    username         text not null,
+    company          text not null,
+    type             text not null,
+    scheduled_at     timestamptz not null,
+    timezone         text not null default 'UTC',
+    duration_minutes integer not null default 60 check (duration_minutes > 0),
+    location         text not null default '',
+    video_link       text not null default '',
+    interviewers     text[] not null default '{}',
+    outcome          text not null default 'pending',
+    feedback         text not null default '',
+    created_at       timestamptz not null default now(),
+    foreign key (username, company) references applications (username, company) on delete cascade
+);
+
+create index if not exists interviews_username_scheduled_at_idx on interviews (username, scheduled_at);
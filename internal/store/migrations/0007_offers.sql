@@ -0,0 +1,18 @@
+create table if not exists offers (
+    id                   bigint generated always as identity primary key,
+    username             text not null,
+    company              text not null,
+    currency             char(3) not null,
+    base_salary          numeric(14, 2) not null check (base_salary >= 0),
+    salary_period        text not null default 'year',
+    bonus                numeric(14, 2) not null default 0 check (bonus >= 0),
+    equity               numeric(14, 2) not null default 0 check (equity >= 0),
+    equity_vesting_years integer not null default 4 check (equity_vesting_years > 0),
+    start_date           date,
+    expires_on           date,
+    benefits             text not null default '',
+    created_at           timestamptz not null default now(),
+    foreign key (username, company) references applications (username, company) on delete cascade
+);
+
+create index if not exists offers_username_company_idx on offers (username, company);
@@ -0,0 +1,15 @@
+create table if not exists attachments (
+    id           bigint generated always as identity primary key,
+    username     text not null,
+    company      text not null,
+    kind         text not null default 'other',
+    filename     text not null,
+    content_type text not null,
+    size_bytes   bigint not null check (size_bytes >= 0),
+    sha256       char(64) not null,
+    storage_key  text not null unique,
+    created_at   timestamptz not null default now(),
+    foreign key (username, company) references applications (username, company) on delete cascade
+);
+
+create index if not exists attachments_username_company_idx on attachments (username, company);
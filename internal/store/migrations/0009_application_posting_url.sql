@@ -0,0 +1 @@
+alter table applications add column if not exists posting_url text not null default '';
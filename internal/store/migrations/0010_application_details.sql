@@ -0,0 +1,18 @@
+alter table applications
+    add column if not exists salary_min      numeric(14, 2),
+    add column if not exists salary_max      numeric(14, 2),
+    add column if not exists salary_currency char(3),
+    add column if not exists salary_period   text,
+    add column if not exists location        text not null default '',
+    add column if not exists remote_policy   text not null default '',
+    add column if not exists employment_type text not null default '',
+    add column if not exists source          text not null default '';
+
+alter table applications
+    drop constraint if exists applications_salary_range_check,
+    add constraint applications_salary_range_check check (
+        (salary_min is null and salary_max is null and salary_currency is null and salary_period is null)
+        or (salary_min >= 0 and salary_max >= salary_min and salary_currency is not null and salary_period is not null)
+    );
+
+create index if not exists applications_username_remote_policy_idx on applications (username, remote_policy);
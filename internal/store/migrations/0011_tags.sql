@@ -0,0 +1,19 @@
+create table if not exists tags (
+    id         bigint generated always as identity primary key,
+    username   text not null references users (username) on delete cascade,
+    name       text not null,
+    color      text not null default '#808080',
+    created_at timestamptz not null default now()
+);
+
+create unique index if not exists tags_username_name_idx on tags (username, lower(name));
+
+create table if not exists application_tags (
+    username text not null,
+    company  text not null,
+    tag_id   bigint not null references tags (id) on delete cascade,
+    primary key (username, company, tag_id),
+    foreign key (username, company) references applications (username, company) on delete cascade
+);
+
+create index if not exists application_tags_tag_id_idx on application_tags (tag_id);
@@ -0,0 +1,12 @@
+create table if not exists custom_fields (
+    id         bigint generated always as identity primary key,
+    username   text not null references users (username) on delete cascade,
+    name       text not null,
+    type       text not null,
+    options    text[] not null default '{}',
+    created_at timestamptz not null default now()
+);
+
+create unique index if not exists custom_fields_username_name_idx on custom_fields (username, lower(name));
+
+alter table applications add column if not exists custom_fields jsonb not null default '{}';
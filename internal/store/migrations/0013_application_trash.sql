@@ -0,0 +1,4 @@
+alter table applications add column if not exists deleted_at timestamptz;
+
+-- Only trashed rows are indexed, for the trash listing and the purge.
+create index if not exists applications_deleted_at_idx on applications (username, deleted_at) where deleted_at is not null;
@@ -0,0 +1,13 @@
+alter table applications add column if not exists status_changed_at timestamptz;
+
+-- Best guess for existing applications: the status was set by their last change.
+update applications set status_changed_at = updated_at where status_changed_at is null;
+
+alter table applications
+    alter column status_changed_at set default now(),
+    alter column status_changed_at set not null,
+    add column if not exists archived_at   timestamptz,
+    add column if not exists unarchived_at timestamptz; -- Restarts the auto-archive clock, so unarchiving sticks.
+
+create index if not exists applications_archive_candidates_idx on applications (status, status_changed_at)
+    where archived_at is null and deleted_at is null;
@@ -0,0 +1,2 @@
+-- Bumped by every update, for optimistic concurrency control through ETags.
+alter table applications add column if not exists version bigint not null default 1;
@@ -0,0 +1,12 @@
+create table if not exists idempotency_keys (
+    username     text not null references users (username) on delete cascade,
+    key          text not null,
+    request_hash bytea not null,
+    status_code  integer, -- Null while the first request is still being handled.
+    content_type text not null default '',
+    body         bytea not null default '',
+    expires_at   timestamptz not null,
+    primary key (username, key)
+);
+
+create index if not exists idempotency_keys_expires_at_idx on idempotency_keys (expires_at);
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// A calendar date without a time, marshalled as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(date.Format(time.DateOnly))
+}
+
+func (date *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return errors.New("dates must be formatted YYYY-MM-DD")
+	}
+
+	date.Time = parsed
+	return nil
+}
+
+// Returns nil for a nil date, so optional dates can be passed straight to SQL.
+func (date *Date) sqlValue() *time.Time {
+	if date == nil {
+		return nil
+	}
+	return &date.Time
+}
+
+func dateFromSQL(value *time.Time) *Date {
+	if value == nil {
+		return nil
+	}
+	return &Date{*value}
+}
+
+// Type for standardising how often a salary is paid.
+type PayPeriod string
+
+const (
+	PerYear  PayPeriod = "year"
+	PerMonth PayPeriod = "month"
+	PerWeek  PayPeriod = "week"
+	PerHour  PayPeriod = "hour"
+)
+
+func GetSupportedPayPeriods() []PayPeriod {
+	return []PayPeriod{PerYear, PerMonth, PerWeek, PerHour}
+}
+
+// How many of each period make up a year, assuming 40 hour weeks.
+var periodsPerYear = map[PayPeriod]float64{
+	PerYear:  1,
+	PerMonth: 12,
+	PerWeek:  52,
+	PerHour:  52 * 40,
+}
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+const defaultEquityVestingYears int = 4
+
+// An offer received for an application. Amounts are in Currency.
+type JobOffer struct {
+	ID                 int64     `json:"id"`
+	Company            string    `json:"company"`  // The application the offer is for.
+	Currency           string    `json:"currency"` // ISO 4217 code, e.g. "GBP".
+	BaseSalary         float64   `json:"base_salary"`
+	SalaryPeriod       PayPeriod `json:"salary_period"`
+	Bonus              float64   `json:"bonus"`  // Expected yearly bonus.
+	Equity             float64   `json:"equity"` // Total value of the grant, vesting over EquityVestingYears.
+	EquityVestingYears int       `json:"equity_vesting_years"`
+	StartDate          *Date     `json:"start_date"`
+	ExpiresOn          *Date     `json:"expires_on"` // Last day to accept.
+	Benefits           string    `json:"benefits"`
+}
+
+// Checks the offer's fields, filling in defaults.
+func (offer *JobOffer) Validate() error {
+	offer.Currency = strings.ToUpper(strings.TrimSpace(offer.Currency))
+	if !currencyCodePattern.MatchString(offer.Currency) {
+		return errors.New("`currency` must be a three letter ISO 4217 code")
+	}
+
+	if offer.BaseSalary < 0 || offer.Bonus < 0 || offer.Equity < 0 {
+		return errors.New("`base_salary`, `bonus` and `equity` must not be negative")
+	}
+
+	if offer.SalaryPeriod == "" {
+		offer.SalaryPeriod = PerYear
+	}
+	if !slices.Contains(GetSupportedPayPeriods(), offer.SalaryPeriod) {
+		return errors.New("`salary_period` is not supported by type PayPeriod")
+	}
+
+	if offer.EquityVestingYears == 0 {
+		offer.EquityVestingYears = defaultEquityVestingYears
+	}
+	if offer.EquityVestingYears < 0 {
+		return errors.New("`equity_vesting_years` must be positive")
+	}
+
+	return nil
+}
+
+// Yearly compensation, in the offer's currency.
+type AnnualCompensation struct {
+	Base   float64
+	Bonus  float64
+	Equity float64 // The grant spread evenly over its vesting period.
+	Total  float64
+}
+
+func (offer *JobOffer) Annualised() AnnualCompensation {
+	compensation := AnnualCompensation{
+		Base:   offer.BaseSalary * periodsPerYear[offer.SalaryPeriod],
+		Bonus:  offer.Bonus,
+		Equity: offer.Equity / float64(max(offer.EquityVestingYears, 1)),
+	}
+	compensation.Total = compensation.Base + compensation.Bonus + compensation.Equity
+
+	return compensation
+}
+
+const offerColumns string = "id, company, currency, base_salary, salary_period, bonus, equity, equity_vesting_years, start_date, expires_on, benefits"
+
+func scanOffer(row pgx.Row) (*JobOffer, error) {
+	offer := &JobOffer{}
+	var start_date *time.Time
+	var expires_on *time.Time
+
+	err := row.Scan(
+		&offer.ID,
+		&offer.Company,
+		&offer.Currency,
+		&offer.BaseSalary,
+		&offer.SalaryPeriod,
+		&offer.Bonus,
+		&offer.Equity,
+		&offer.EquityVestingYears,
+		&start_date,
+		&expires_on,
+		&offer.Benefits,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	offer.StartDate = dateFromSQL(start_date)
+	offer.ExpiresOn = dateFromSQL(expires_on)
+
+	return offer, nil
+}
+
+// Lists offers for the application, or every application when `companyID` is empty.
+// Offers for trashed applications are hidden along with them.
+func (db *DB) ListOffers(ctx context.Context, username string, companyID string) ([]*JobOffer, error) {
+	sql := "select " + offerColumns + " from offers where username=$1 and " + notTrashed("offers")
+	args := []any{username}
+	if companyID != "" {
+		args = append(args, companyID)
+		sql += " and company=$" + strconv.Itoa(len(args))
+	}
+
+	rows, err := db.conn().Query(ctx, sql+" order by company, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	offers := []*JobOffer{}
+
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, rows.Err()
+}
+
+func (db *DB) GetOffer(ctx context.Context, username string, companyID string, offerID int64) (*JobOffer, error) {
+	return scanOffer(db.conn().QueryRow(ctx, "select "+offerColumns+" from offers where id=$1 and username=$2 and company=$3 and "+notTrashed("offers"), offerID, username, companyID))
+}
+
+// Saves a new offer for offer.Company, setting its ID.
+func (db *DB) CreateOffer(ctx context.Context, username string, offer *JobOffer) error {
+	// Selected through the live application, so a trashed one can't gain offers.
+	err := db.conn().QueryRow(ctx, `insert into offers (username, company, currency, base_salary, salary_period, bonus, equity, equity_vesting_years, start_date, expires_on, benefits)
+		select username, company, $3, $4, $5, $6, $7, $8, $9, $10, $11 from applications where username=$1 and company=$2 and deleted_at is null
+		returning id`,
+		username,
+		offer.Company,
+		offer.Currency,
+		offer.BaseSalary,
+		offer.SalaryPeriod,
+		offer.Bonus,
+		offer.Equity,
+		offer.EquityVestingYears,
+		offer.StartDate.sqlValue(),
+		offer.ExpiresOn.sqlValue(),
+		offer.Benefits,
+	).Scan(&offer.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// Replaces every field of the offer with ID offer.ID.
+func (db *DB) UpdateOffer(ctx context.Context, username string, offer *JobOffer) error {
+	command_tag, err := db.conn().Exec(ctx, `update offers set currency=$1, base_salary=$2, salary_period=$3, bonus=$4, equity=$5, equity_vesting_years=$6, start_date=$7, expires_on=$8, benefits=$9
+		where id=$10 and username=$11 and company=$12 and `+notTrashed("offers"),
+		offer.Currency,
+		offer.BaseSalary,
+		offer.SalaryPeriod,
+		offer.Bonus,
+		offer.Equity,
+		offer.EquityVestingYears,
+		offer.StartDate.sqlValue(),
+		offer.ExpiresOn.sqlValue(),
+		offer.Benefits,
+		offer.ID,
+		username,
+		offer.Company,
+	)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteOffer(ctx context.Context, username string, companyID string, offerID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from offers where id=$1 and username=$2 and company=$3 and "+notTrashed("offers"), offerID, username, companyID)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
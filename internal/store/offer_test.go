@@ -0,0 +1,56 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJobOfferValidate(t *testing.T) {
+	offer := &JobOffer{Currency: " gbp ", BaseSalary: 5000, SalaryPeriod: PerMonth}
+
+	err := offer.Validate()
+	if err != nil {
+		t.Fatalf("Expected a valid offer, got %v", err)
+	}
+	if offer.Currency != "GBP" || offer.EquityVestingYears != defaultEquityVestingYears {
+		t.Errorf("Expected the currency to be normalised and defaults filled in, got %+v", offer)
+	}
+
+	for name, invalid := range map[string]JobOffer{
+		"bad currency":   {Currency: "Pounds", BaseSalary: 1},
+		"negative bonus": {Currency: "GBP", Bonus: -1},
+		"bad period":     {Currency: "GBP", SalaryPeriod: "fortnight"},
+	} {
+		if invalid.Validate() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestJobOfferAnnualised(t *testing.T) {
+	offer := &JobOffer{Currency: "USD", BaseSalary: 50, SalaryPeriod: PerHour, Bonus: 10000, Equity: 40000, EquityVestingYears: 4}
+
+	annual := offer.Annualised()
+
+	if annual.Base != 104000 || annual.Equity != 10000 || annual.Total != 124000 {
+		t.Errorf("Unexpected annual compensation %+v", annual)
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	var offer JobOffer
+	err := json.Unmarshal([]byte(`{"start_date": "2025-09-01"}`), &offer)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	marshalled, err := json.Marshal(offer.StartDate)
+	if err != nil || string(marshalled) != `"2025-09-01"` {
+		t.Errorf("Expected the date to round-trip, got %s (%v)", marshalled, err)
+	}
+
+	err = json.Unmarshal([]byte(`{"start_date": "2025-09-01T00:00:00Z"}`), &offer)
+	if err == nil {
+		t.Errorf("Expected an error for a full timestamp")
+	}
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Wraps matched terms in search snippets.
+const (
+	SnippetStart string = "<mark>"
+	SnippetStop  string = "</mark>"
+
+	// Mark matches before the text is escaped. Control characters, which are stripped from the text first.
+	rawSnippetStart string = "\x02"
+	rawSnippetStop  string = "\x03"
+)
+
+// An application matching a search, with how well it matched and an excerpt showing where.
+type SearchResult struct {
+	Application *JobApplication
+	Rank        float64
+	Snippet     string // HTML-escaped text with matches wrapped in SnippetStart and SnippetStop.
+}
+
+// Searches company, role and notes with Postgres full-text search, best matches first.
+// `query` takes web search syntax: quoted phrases, `or`, and `-` to exclude a term.
+func (db *DB) SearchApplications(ctx context.Context, username string, query string, limit int) ([]SearchResult, error) {
+	rows, err := db.conn().Query(ctx, "select "+applicationColumns+`,
+			ts_rank(search_vector, query),
+			ts_headline('english', translate(company || ' · ' || role || ' · ' || applications_notes_text(notes), chr(2) || chr(3), ''), query, $3)
+		from applications, websearch_to_tsquery('english', $2) query
+		where username=$1 and deleted_at is null and search_vector @@ query
+		order by ts_rank(search_vector, query) desc, company
+		limit $4`,
+		username,
+		query,
+		`StartSel="`+rawSnippetStart+`", StopSel="`+rawSnippetStop+`", MaxFragments=2, MaxWords=20, MinWords=5`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+
+	for rows.Next() {
+		var result SearchResult
+
+		result.Application, err = scanApplication(rowWithExtras{rows, []any{&result.Rank, &result.Snippet}})
+		if err != nil {
+			return nil, err
+		}
+		result.Snippet = markSnippet(result.Snippet)
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// Lets scanApplication read rows that select extra columns after applicationColumns.
+type rowWithExtras struct {
+	row    pgx.Row
+	extras []any
+}
+
+func (row rowWithExtras) Scan(dest ...any) error {
+	return row.row.Scan(append(dest, row.extras...)...)
+}
+
+// Weights per field, matching Postgres's default ts_rank weights for A, B and C.
+var searchFieldWeights = [3]float64{1.0, 0.4, 0.2}
+
+// Approximates SearchApplications for FakeStore: every term must appear somewhere, as a case-insensitive substring.
+func searchApplication(job_application *JobApplication, terms []string) (SearchResult, bool) {
+	fields := [3]string{job_application.company, string(job_application.role), strings.Join(job_application.notes, " ")}
+	result := SearchResult{Application: job_application}
+
+	for _, term := range terms {
+		found := false
+		for i, field := range fields {
+			count := strings.Count(strings.ToLower(field), term)
+			if count > 0 {
+				found = true
+				result.Rank += searchFieldWeights[i] * float64(count)
+			}
+		}
+		if !found {
+			return result, false
+		}
+	}
+
+	text := strings.NewReplacer(rawSnippetStart, "", rawSnippetStop, "").Replace(strings.Join(fields[:], " · "))
+	result.Snippet = markSnippet(highlightTerms(text, terms))
+	return result, true
+}
+
+// Splits a search query into lowercase terms, ignoring punctuation.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func highlightTerms(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return text // Lowercasing changed byte offsets, so matches can't be mapped back.
+	}
+
+	var highlighted strings.Builder
+
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, term := range terms {
+			if strings.HasPrefix(lower[i:], term) && len(term) > matched {
+				matched = len(term)
+			}
+		}
+
+		if matched == 0 {
+			highlighted.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		highlighted.WriteString(rawSnippetStart + text[i:i+matched] + rawSnippetStop)
+		i += matched
+	}
+
+	return highlighted.String()
+}
+
+// Escapes a snippet marked with rawSnippetStart and rawSnippetStop, so the text can't inject HTML,
+// then swaps the marks for SnippetStart and SnippetStop.
+func markSnippet(raw string) string {
+	var snippet strings.Builder
+	open := false
+
+	for {
+		i := strings.IndexAny(raw, rawSnippetStart+rawSnippetStop)
+		if i < 0 {
+			snippet.WriteString(html.EscapeString(raw))
+			break
+		}
+
+		snippet.WriteString(html.EscapeString(raw[:i]))
+		if raw[i:i+1] == rawSnippetStart && !open {
+			snippet.WriteString(SnippetStart)
+			open = true
+		} else if raw[i:i+1] == rawSnippetStop && open {
+			snippet.WriteString(SnippetStop)
+			open = false
+		}
+		raw = raw[i+1:]
+	}
+
+	if open {
+		snippet.WriteString(SnippetStop)
+	}
+
+	return snippet.String()
+}
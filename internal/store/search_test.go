@@ -0,0 +1,20 @@
+package store
+
+import "testing"
+
+func TestMarkSnippet(t *testing.T) {
+	cases := map[string]string{
+		"Knows \x02Go\x03 well":              "Knows <mark>Go</mark> well",
+		"<img src=x onerror=\x02alert\x03>":  "&lt;img src=x onerror=<mark>alert</mark>&gt;",
+		"R&D \x02team\x03's \"lead\"":        "R&amp;D <mark>team</mark>&#39;s &#34;lead&#34;",
+		"\x03stray\x03 \x02unclosed":         "stray <mark>unclosed</mark>",
+		"\x02nested \x02twice\x03\x03 after": "<mark>nested twice</mark> after",
+	}
+
+	for raw, expected := range cases {
+		snippet := markSnippet(raw)
+		if snippet != expected {
+			t.Errorf("markSnippet(%q): expected %q, got %q", raw, expected, snippet)
+		}
+	}
+}
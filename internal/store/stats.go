@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Application counts for one week, starting Monday 00:00 UTC.
+type WeeklyCount struct {
+	WeekStart time.Time
+	Count     int
+}
+
+// Aggregates over a user's applications, for dashboards.
+type ApplicationStats struct {
+	Total     int
+	ByStatus  map[ApplicationStatus]int
+	ByRole    map[JobRole]int
+	Responded int // Applications the employer has replied to, see IsEmployerResponse.
+
+	ResponseRate float64 // Responded over Total, 0 with no applications.
+	OfferRate    float64 // Offers over Total, 0 with no applications.
+
+	// Median days from applying to the first response, nil until there's been one.
+	MedianDaysToResponse *float64
+
+	// Applications created each week from the week containing `from` to the week containing `to`, including empty weeks.
+	Weekly []WeeklyCount
+}
+
+// Returns the Monday 00:00 UTC starting the week containing `value`.
+func WeekStart(value time.Time) time.Time {
+	year, month, day := value.UTC().Date()
+	days_since_monday := (int(value.UTC().Weekday()) + 6) % 7
+	return time.Date(year, month, day-days_since_monday, 0, 0, 0, 0, time.UTC)
+}
+
+func (stats *ApplicationStats) computeRates() {
+	if stats.Total == 0 {
+		return
+	}
+	stats.ResponseRate = float64(stats.Responded) / float64(stats.Total)
+	stats.OfferRate = float64(stats.ByStatus[Offer]) / float64(stats.Total)
+}
+
+// Aggregates the user's applications in SQL, sending every query in one round trip.
+func (db *DB) GetApplicationStats(ctx context.Context, username string, from time.Time, to time.Time) (*ApplicationStats, error) {
+	stats := &ApplicationStats{
+		ByStatus: map[ApplicationStatus]int{},
+		ByRole:   map[JobRole]int{},
+	}
+
+	batch := &pgx.Batch{}
+
+	batch.Queue(`select
+			count(*),
+			count(*) filter (where responded_at is not null),
+			percentile_cont(0.5) within group (order by extract(epoch from responded_at - created_at)::float8 / 86400) filter (where responded_at is not null)
+		from applications where username=$1 and deleted_at is null`, username).QueryRow(func(row pgx.Row) error {
+		return row.Scan(&stats.Total, &stats.Responded, &stats.MedianDaysToResponse)
+	})
+
+	batch.Queue("select status, count(*) from applications where username=$1 and deleted_at is null group by status", username).Query(func(rows pgx.Rows) error {
+		var status ApplicationStatus
+		var count int
+		_, err := pgx.ForEachRow(rows, []any{&status, &count}, func() error {
+			stats.ByStatus[status] = count
+			return nil
+		})
+		return err
+	})
+
+	batch.Queue("select role, count(*) from applications where username=$1 and deleted_at is null group by role", username).Query(func(rows pgx.Rows) error {
+		var role JobRole
+		var count int
+		_, err := pgx.ForEachRow(rows, []any{&role, &count}, func() error {
+			stats.ByRole[role] = count
+			return nil
+		})
+		return err
+	})
+
+	batch.Queue(`select week, count(applications.created_at)
+		from generate_series($2::timestamp, $3::timestamp, interval '1 week') week
+		left join applications on applications.username=$1 and applications.deleted_at is null and date_trunc('week', applications.created_at at time zone 'UTC') = week
+		group by week order by week`, username, WeekStart(from), WeekStart(to)).Query(func(rows pgx.Rows) error {
+		var week_start time.Time
+		var count int
+		_, err := pgx.ForEachRow(rows, []any{&week_start, &count}, func() error {
+			stats.Weekly = append(stats.Weekly, WeeklyCount{WeekStart: week_start, Count: count})
+			return nil
+		})
+		return err
+	})
+
+	err := db.conn().SendBatch(ctx, batch).Close()
+	if err != nil {
+		return nil, err
+	}
+
+	stats.computeRates()
+	return stats, nil
+}
+
+// Computes the same aggregates as DB.GetApplicationStats in memory, for FakeStore.
+func computeApplicationStats(applications []*JobApplication, from time.Time, to time.Time) *ApplicationStats {
+	stats := &ApplicationStats{
+		Total:    len(applications),
+		ByStatus: map[ApplicationStatus]int{},
+		ByRole:   map[JobRole]int{},
+	}
+
+	weekly := map[time.Time]int{}
+	response_days := []float64{}
+
+	for _, application := range applications {
+		stats.ByStatus[application.status]++
+		stats.ByRole[application.role]++
+		weekly[WeekStart(application.createdAt)]++
+
+		if !application.respondedAt.IsZero() {
+			stats.Responded++
+			response_days = append(response_days, application.respondedAt.Sub(application.createdAt).Hours()/24)
+		}
+	}
+
+	if len(response_days) > 0 {
+		median_days := median(response_days)
+		stats.MedianDaysToResponse = &median_days
+	}
+
+	for week := WeekStart(from); !week.After(WeekStart(to)); week = week.AddDate(0, 0, 7) {
+		stats.Weekly = append(stats.Weekly, WeeklyCount{WeekStart: week, Count: weekly[week]})
+	}
+
+	stats.computeRates()
+	return stats
+}
+
+// Interpolates between the middle two values for even lengths, like percentile_cont(0.5).
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	cases := map[time.Time]time.Time{
+		time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC): time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), // Wednesday
+		time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC):  time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), // Monday
+		time.Date(2025, 3, 16, 23, 0, 0, 0, time.UTC): time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), // Sunday
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC):   time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	for value, expected := range cases {
+		if !WeekStart(value).Equal(expected) {
+			t.Errorf("WeekStart(%v): expected %v, got %v", value, expected, WeekStart(value))
+		}
+	}
+}
+
+func TestComputeApplicationStats(t *testing.T) {
+	week := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	new_application := func(company string, status ApplicationStatus, created_at time.Time, response_days int) *JobApplication {
+		job_application, err := NewJobApplication(company, SoftwareEngineer, status, []string{})
+		if err != nil {
+			t.Fatalf("Failed to create NewJobApplication: %v", err)
+		}
+		job_application.SetTimestamps(created_at, created_at)
+		if response_days > 0 {
+			job_application.SetRespondedAt(created_at.AddDate(0, 0, response_days))
+		}
+		return job_application
+	}
+
+	applications := []*JobApplication{
+		new_application("One", PendingResponse, week, 0),
+		new_application("Two", Rejected, week, 3),
+		new_application("Three", Offer, week.AddDate(0, 0, 14), 10),
+		new_application("Four", Active, week.AddDate(0, 0, 14), 4),
+	}
+
+	stats := computeApplicationStats(applications, week, week.AddDate(0, 0, 14))
+
+	if stats.Total != 4 || stats.Responded != 3 {
+		t.Errorf("Unexpected totals: %d total, %d responded", stats.Total, stats.Responded)
+	}
+	if stats.ByStatus[Offer] != 1 || stats.ByRole[SoftwareEngineer] != 4 {
+		t.Errorf("Unexpected breakdowns: %v, %v", stats.ByStatus, stats.ByRole)
+	}
+	if stats.ResponseRate != 0.75 || stats.OfferRate != 0.25 {
+		t.Errorf("Unexpected rates: %v response, %v offer", stats.ResponseRate, stats.OfferRate)
+	}
+	if stats.MedianDaysToResponse == nil || *stats.MedianDaysToResponse != 4 {
+		t.Errorf("Expected a median of 4 days, got %v", stats.MedianDaysToResponse)
+	}
+
+	expected_weekly := []int{2, 0, 2}
+	if len(stats.Weekly) != len(expected_weekly) {
+		t.Fatalf("Expected %d weeks, got %d", len(expected_weekly), len(stats.Weekly))
+	}
+	for i, count := range expected_weekly {
+		if stats.Weekly[i].Count != count {
+			t.Errorf("Week %d: expected %d, got %d", i, count, stats.Weekly[i].Count)
+		}
+	}
+}
+
+func TestComputeApplicationStatsEmpty(t *testing.T) {
+	stats := computeApplicationStats(nil, time.Now(), time.Now())
+
+	if stats.ResponseRate != 0 || stats.MedianDaysToResponse != nil {
+		t.Errorf("Expected zero rates and no median, got %+v", stats)
+	}
+	if len(stats.Weekly) != 1 {
+		t.Errorf("Expected a single empty week, got %v", stats.Weekly)
+	}
+}
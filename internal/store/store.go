@@ -4,148 +4,413 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/medidew/ApplicationTracker/internal/auth"
 )
 
+// Methods taking an `expectedVersion` only apply if the application is still at that version,
+// returning ErrVersionMismatch otherwise. 0 skips the check.
 type Store interface {
-	ListApplications(username string) ([]*JobApplication, error)
-	GetApplication(username string, companyID string) (*JobApplication, error)
-	CreateApplication(username string, application *JobApplication) error
-	DeleteApplication(username string, companyID string) error
-	UpdateApplicationStatus(username string, companyID string, status ApplicationStatus) error
-	AddApplicationNote(username string, companyID string, note string) error
-	RemoveApplicationNote(username string, companyID string, noteIndex int) error
-	ListApplicationNotes(username string, companyID string) ([]string, error)
-
-	CreateUser(email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error
-	GetUserHashedPassword(username string) ([]byte, error)
-	GetUserArgon2Auth(username string) (*auth.Argon2Auth, error)
+	ListApplications(ctx context.Context, username string, filter ApplicationFilter) ([]*JobApplication, error)
+	StreamApplications(ctx context.Context, username string, filter ApplicationFilter, yield func(*JobApplication) error) error
+	SearchApplications(ctx context.Context, username string, query string, limit int) ([]SearchResult, error)
+	GetApplication(ctx context.Context, username string, companyID string) (*JobApplication, error)
+	CreateApplication(ctx context.Context, username string, application *JobApplication) error
+	DeleteApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error
+	ArchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error
+	UnarchiveApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error
+	ArchiveStaleApplications(ctx context.Context, rules []ArchiveRule, now time.Time) (int64, error)
+	ListTrashedApplications(ctx context.Context, username string) ([]*JobApplication, error)
+	RestoreApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error
+	PurgeDeletedApplications(ctx context.Context, deletedBefore time.Time) (int64, []string, error)
+	UpdateApplicationStatus(ctx context.Context, username string, companyID string, status ApplicationStatus, expectedVersion int64) error
+	SetApplicationFollowUp(ctx context.Context, username string, companyID string, followUpAt time.Time, expectedVersion int64) error
+	UpdateApplicationDetails(ctx context.Context, username string, companyID string, details ApplicationDetails, expectedVersion int64) error
+	UpdateApplicationCustomFields(ctx context.Context, username string, companyID string, values map[string]any, expectedVersion int64) error
+	AddApplicationNote(ctx context.Context, username string, companyID string, note string, expectedVersion int64) error
+	RemoveApplicationNote(ctx context.Context, username string, companyID string, noteIndex int, expectedVersion int64) error
+	ListApplicationNotes(ctx context.Context, username string, companyID string) ([]string, error)
+	ImportApplications(ctx context.Context, username string, applications []*JobApplication, dryRun bool) ([]ImportOutcome, error)
+	GetApplicationStats(ctx context.Context, username string, from time.Time, to time.Time) (*ApplicationStats, error)
+
+	ListContacts(ctx context.Context, username string) ([]*Contact, error)
+	GetContact(ctx context.Context, username string, contactID int64) (*Contact, error)
+	CreateContact(ctx context.Context, username string, contact *Contact) error
+	UpdateContact(ctx context.Context, username string, contact *Contact) error
+	DeleteContact(ctx context.Context, username string, contactID int64) error
+	ListApplicationContacts(ctx context.Context, username string, companyID string) ([]*Contact, error)
+	LinkContact(ctx context.Context, username string, companyID string, contactID int64) error
+	UnlinkContact(ctx context.Context, username string, companyID string, contactID int64) error
+
+	ListCustomFields(ctx context.Context, username string) ([]*CustomField, error)
+	GetCustomField(ctx context.Context, username string, fieldID int64) (*CustomField, error)
+	CreateCustomField(ctx context.Context, username string, field *CustomField) error
+	UpdateCustomField(ctx context.Context, username string, field *CustomField) error
+	DeleteCustomField(ctx context.Context, username string, fieldID int64) error
+
+	ListTags(ctx context.Context, username string) ([]*Tag, error)
+	GetTag(ctx context.Context, username string, tagID int64) (*Tag, error)
+	CreateTag(ctx context.Context, username string, tag *Tag) error
+	UpdateTag(ctx context.Context, username string, tag *Tag) error
+	DeleteTag(ctx context.Context, username string, tagID int64) error
+	ListApplicationTags(ctx context.Context, username string, companyID string) ([]*Tag, error)
+	TagApplication(ctx context.Context, username string, companyID string, tagID int64) error
+	UntagApplication(ctx context.Context, username string, companyID string, tagID int64) error
+
+	ListInterviews(ctx context.Context, username string, filter InterviewFilter) ([]*Interview, error)
+	GetInterview(ctx context.Context, username string, companyID string, interviewID int64) (*Interview, error)
+	CreateInterview(ctx context.Context, username string, interview *Interview, advanceStatus bool) error
+	UpdateInterview(ctx context.Context, username string, interview *Interview) error
+	DeleteInterview(ctx context.Context, username string, companyID string, interviewID int64) error
+
+	ListOffers(ctx context.Context, username string, companyID string) ([]*JobOffer, error)
+	GetOffer(ctx context.Context, username string, companyID string, offerID int64) (*JobOffer, error)
+	CreateOffer(ctx context.Context, username string, offer *JobOffer) error
+	UpdateOffer(ctx context.Context, username string, offer *JobOffer) error
+	DeleteOffer(ctx context.Context, username string, companyID string, offerID int64) error
+
+	ListAttachments(ctx context.Context, username string, companyID string) ([]*Attachment, error)
+	GetAttachment(ctx context.Context, username string, companyID string, attachmentID int64) (*Attachment, error)
+	CreateAttachment(ctx context.Context, username string, attachment *Attachment) error
+	DeleteAttachment(ctx context.Context, username string, companyID string, attachmentID int64) error
+
+	BeginIdempotentRequest(ctx context.Context, username string, key string, requestHash []byte, expiresAt time.Time) (*IdempotentResponse, error)
+	CompleteIdempotentRequest(ctx context.Context, username string, key string, response *IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, username string, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+
+	CreateUser(ctx context.Context, email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error
+	GetUserHashedPassword(ctx context.Context, username string) ([]byte, error)
+	GetUserArgon2Auth(ctx context.Context, username string) (*auth.Argon2Auth, error)
+
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+
+	Ping(ctx context.Context) error
+	PendingMigrations(ctx context.Context) ([]string, error)
+	CountApplicationsByStatus(ctx context.Context) (map[ApplicationStatus]int, error)
 }
 
 type DB struct {
 	Pool	*pgxpool.Pool
+	tx	pgx.Tx // Set on the DB passed to a WithTx callback, so its queries run in the transaction.
+}
+
+func (db *DB) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
 }
 
-func (db *DB) ListApplications(username string) ([]*JobApplication, error) {
-	rows, err := db.Pool.Query(context.Background(), "select company, role, status, notes from applications where username=$1", username)
+// Columns selected for a JobApplication, in the order scanApplication expects.
+const applicationColumns string = "company, role, status, notes, created_at, updated_at, follow_up_at, responded_at, posting_url, " +
+	"salary_min, salary_max, salary_currency, salary_period, location, remote_policy, employment_type, source, custom_fields, status_changed_at, archived_at, unarchived_at, deleted_at, version"
+
+func scanApplication(row pgx.Row) (*JobApplication, error) {
+	var company string
+	var role JobRole
+	var status ApplicationStatus
+	var notes []string
+	var created_at time.Time
+	var updated_at time.Time
+	var follow_up_at *time.Time
+	var responded_at *time.Time
+	var posting_url string
+	var salary_min *float64
+	var salary_max *float64
+	var salary_currency *string
+	var salary_period *PayPeriod
+	details := ApplicationDetails{}
+	var custom_fields map[string]any
+	var status_changed_at time.Time
+	var archived_at *time.Time
+	var unarchived_at *time.Time
+	var deleted_at *time.Time
+	var version int64
+
+	err := row.Scan(&company, &role, &status, &notes, &created_at, &updated_at, &follow_up_at, &responded_at, &posting_url,
+		&salary_min, &salary_max, &salary_currency, &salary_period, &details.Location, &details.RemotePolicy, &details.EmploymentType, &details.Source, &custom_fields, &status_changed_at, &archived_at, &unarchived_at, &deleted_at, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	applications := []*JobApplication{}
+	if salary_min != nil && salary_max != nil && salary_currency != nil && salary_period != nil {
+		details.Salary = &SalaryRange{Min: *salary_min, Max: *salary_max, Currency: *salary_currency, Period: *salary_period}
+	}
 
-	for rows.Next() {
-		var company string
-		var role JobRole
-		var status ApplicationStatus
-		var notes []string
+	job_application, err := NewJobApplication(company, role, status, notes, details)
+	if err != nil {
+		return nil, err
+	}
 
-		err = rows.Scan(&company, &role, &status, &notes)
-		if err != nil {
-			return nil, err
-		}
+	job_application.SetTimestamps(created_at, updated_at)
+	if follow_up_at != nil {
+		job_application.SetFollowUpAt(*follow_up_at)
+	}
+	if responded_at != nil {
+		job_application.SetRespondedAt(*responded_at)
+	}
+	job_application.postingURL = posting_url
+	job_application.customFields = custom_fields
+	job_application.SetStatusChangedAt(status_changed_at)
+	job_application.SetVersion(version)
+	if archived_at != nil {
+		job_application.SetArchivedAt(*archived_at)
+	}
+	if unarchived_at != nil {
+		job_application.unarchivedAt = *unarchived_at
+	}
+	if deleted_at != nil {
+		job_application.SetDeletedAt(*deleted_at)
+	}
 
-		job_application, err := NewJobApplication(company, role, status, notes)
-		if err != nil {
-			return nil, err
-		}
+	return job_application, nil
+}
+
+func (db *DB) ListApplications(ctx context.Context, username string, filter ApplicationFilter) ([]*JobApplication, error) {
+	applications := []*JobApplication{}
 
+	err := db.StreamApplications(ctx, username, filter, func(job_application *JobApplication) error {
 		applications = append(applications, job_application)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return applications, nil
 }
 
-func (db *DB) GetApplication(username string, companyID string) (*JobApplication, error) {
-	var role JobRole
-	var status ApplicationStatus
-	var notes []string
-	err := db.Pool.QueryRow(context.Background(), "select role, status, notes from applications where company=$1 and username=$2", companyID, username).Scan(&role, &status, &notes)
+// Calls `yield` for each matching application as it's read, without loading them all into memory.
+// Stops early if `yield` returns an error, which is returned.
+func (db *DB) StreamApplications(ctx context.Context, username string, filter ApplicationFilter, yield func(*JobApplication) error) error {
+	conditions, args := filter.sqlConditions([]any{username})
+	conditions = append([]string{"username=$1", "deleted_at is null"}, conditions...)
+
+	rows, err := db.conn().Query(ctx, "select "+applicationColumns+" from applications where "+strings.Join(conditions, " and ")+" order by created_at, company", args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer rows.Close()
 
-	job_application, err := NewJobApplication(companyID, role, status, notes)
-	if err != nil {
-		return nil, err
+	for rows.Next() {
+		job_application, err := scanApplication(rows)
+		if err != nil {
+			return err
+		}
+
+		err = yield(job_application)
+		if err != nil {
+			return err
+		}
 	}
 
-	return job_application, nil
+	return rows.Err()
+}
+
+func (db *DB) GetApplication(ctx context.Context, username string, companyID string) (*JobApplication, error) {
+	return scanApplication(db.conn().QueryRow(ctx, "select "+applicationColumns+" from applications where company=$1 and username=$2 and deleted_at is null", companyID, username))
 }
 
-func (db *DB) CreateApplication(username string, application *JobApplication) error {
-	_, err := db.Pool.Exec(context.Background(), "insert into applications (company, role, status, notes, username) values ($1, $2, $3, $4, $5)",
+func (db *DB) CreateApplication(ctx context.Context, username string, application *JobApplication) error {
+	args := []any{
 		application.GetCompany(),
 		application.GetRole(),
 		application.GetStatus(),
 		application.GetNotes(),
+		nullableTime(application.GetFollowUpAt()),
+		application.GetPostingURL(),
+		nonNilValues(application.GetCustomFields()),
 		username,
-	)
+	}
+	args = append(args, detailsArgs(application.GetDetails())...)
+
+	command_tag, err := db.conn().Exec(ctx, `insert into applications (company, role, status, notes, follow_up_at, posting_url, custom_fields, username,
+			salary_min, salary_max, salary_currency, salary_period, location, remote_policy, employment_type, source)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		on conflict (username, company) do nothing`, args...)
 	if err != nil {
 		return err
 	}
+	if command_tag.RowsAffected() == 1 {
+		return nil
+	}
 
-	return nil
+	// A trashed application keeps its row until it's purged, so it can only be restored.
+	var trashed bool
+	err = db.conn().QueryRow(ctx, "select exists (select 1 from applications where company=$1 and username=$2 and deleted_at is not null)", application.GetCompany(), username).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return ErrTrashed
+	}
+
+	return ErrConflict
 }
 
-func (db *DB) DeleteApplication(username string, companyID string) error {
-	_, err := db.Pool.Exec(context.Background(), "delete from applications where company=$1 and username=$2", companyID, username)
+// Moves the application to the trash. It's purged for good by PurgeDeletedApplications.
+func (db *DB) DeleteApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set deleted_at=now(), version=version+1
+		where company=$1 and username=$2 and deleted_at is null and ($3::bigint = 0 or version=$3)`, companyID, username, expectedVersion)
 	if err != nil {
 		return err
 	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
 
 	return nil
 }
 
-func (db *DB) UpdateApplicationStatus(username string, companyID string, status ApplicationStatus) error {
+func (db *DB) UpdateApplicationStatus(ctx context.Context, username string, companyID string, status ApplicationStatus, expectedVersion int64) error {
 	if status > MaxStatus {
 		return errors.New("invalid status value")
 	}
 
-	_, err := db.Pool.Exec(context.Background(), "update applications set status=$1 where company=$2 and username=$3",
+	// `status` on the right-hand side is the old value, matching IsEmployerResponse(old, new).
+	command_tag, err := db.conn().Exec(ctx, `update applications set
+			responded_at = case when responded_at is null and ($1 in ($4, $5) or (status = $6 and $1 = $7)) then now() else responded_at end,
+			status_changed_at = case when status <> $1 then now() else status_changed_at end,
+			status=$1,
+			updated_at=now(),
+			version=version+1
+		where company=$2 and username=$3 and deleted_at is null and ($8::bigint = 0 or version=$8)`,
 		status,
 		companyID,
 		username,
+		Rejected,
+		Offer,
+		PendingResponse,
+		Active,
+		expectedVersion,
+	)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Schedules a follow-up for the application. The zero time clears it.
+func (db *DB) SetApplicationFollowUp(ctx context.Context, username string, companyID string, followUpAt time.Time, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set follow_up_at=$1, updated_at=now(), version=version+1
+		where company=$2 and username=$3 and deleted_at is null and ($4::bigint = 0 or version=$4)`,
+		nullableTime(followUpAt),
+		companyID,
+		username,
+		expectedVersion,
 	)
 	if err != nil {
 		return err
 	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
 
 	return nil
 }
 
-func (db *DB) AddApplicationNote(username string, companyID string, note string) error {
-	_, err := db.Pool.Exec(context.Background(), "update applications set notes = array_append(notes, $1) where company=$2 and username=$3",
+// Replaces the application's salary, location, remote policy, employment type and source.
+func (db *DB) UpdateApplicationDetails(ctx context.Context, username string, companyID string, details ApplicationDetails, expectedVersion int64) error {
+	err := details.Validate()
+	if err != nil {
+		return err
+	}
+
+	args := append(detailsArgs(details), companyID, username, expectedVersion)
+
+	command_tag, err := db.conn().Exec(ctx, `update applications set
+			salary_min=$1, salary_max=$2, salary_currency=$3, salary_period=$4,
+			location=$5, remote_policy=$6, employment_type=$7, source=$8,
+			updated_at=now(), version=version+1
+		where company=$9 and username=$10 and deleted_at is null and ($11::bigint = 0 or version=$11)`, args...)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Orders the details as salary_min, salary_max, salary_currency, salary_period, location, remote_policy,
+// employment_type and source, with nulls for a missing salary.
+func detailsArgs(details ApplicationDetails) []any {
+	args := []any{nil, nil, nil, nil}
+	if details.Salary != nil {
+		args = []any{details.Salary.Min, details.Salary.Max, details.Salary.Currency, details.Salary.Period}
+	}
+
+	return append(args, details.Location, details.RemotePolicy, details.EmploymentType, details.Source)
+}
+
+func (db *DB) AddApplicationNote(ctx context.Context, username string, companyID string, note string, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set notes = array_append(notes, $1), updated_at=now(), version=version+1
+		where company=$2 and username=$3 and deleted_at is null and ($4::bigint = 0 or version=$4)`,
 		note,
 		companyID,
 		username,
+		expectedVersion,
 	)
 	if err != nil {
 		return err
 	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
 
 	return nil
 }
 
-func (db *DB) RemoveApplicationNote(username string, companyID string, noteIndex int) error {
-	_, err := db.Pool.Exec(context.Background(), "update applications set notes = array_remove(notes, notes[$1::int]) where company=$2 and username=$3",
+func (db *DB) RemoveApplicationNote(ctx context.Context, username string, companyID string, noteIndex int, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set notes = array_remove(notes, notes[$1::int]), updated_at=now(), version=version+1
+		where company=$2 and username=$3 and deleted_at is null and ($4::bigint = 0 or version=$4)`,
 		noteIndex,
 		companyID,
 		username,
+		expectedVersion,
 	)
 	if err != nil {
 		return err
 	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedUpdate(ctx, username, companyID, expectedVersion)
+	}
 
 	return nil
 }
 
-func (db *DB) ListApplicationNotes(username string, companyID string) ([]string, error) {
+// Explains why an update guarded by `expectedVersion` changed nothing: ErrVersionMismatch if the application
+// is still there, ErrNotFound if not. Without a version to check, only a missing application changes nothing.
+func (db *DB) explainMissedUpdate(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		return ErrNotFound
+	}
+
+	var exists bool
+	err := db.conn().QueryRow(ctx, "select exists (select 1 from applications where company=$1 and username=$2 and deleted_at is null)", companyID, username).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+
+	return ErrNotFound
+}
+
+func (db *DB) ListApplicationNotes(ctx context.Context, username string, companyID string) ([]string, error) {
 	var notes []string
-	err := db.Pool.QueryRow(context.Background(), "select notes from applications where company=$1 and username=$3", companyID, username).Scan(&notes)
+	err := db.conn().QueryRow(ctx, "select notes from applications where company=$1 and username=$2 and deleted_at is null", companyID, username).Scan(&notes)
 	if err != nil {
 		return nil, err
 	}
@@ -153,13 +418,13 @@ func (db *DB) ListApplicationNotes(username string, companyID string) ([]string,
 	return notes, nil
 }
 
-func (db *DB) CreateUser(email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error {
+func (db *DB) CreateUser(ctx context.Context, email string, username string, argon2auth *auth.Argon2Auth, hashedPassword []byte) error {
 	mem := int(argon2auth.Argon2Memory)
 	time := int(argon2auth.Argon2Time)
 	threads := int(argon2auth.Argon2Threads)
 	salt := base64.RawStdEncoding.EncodeToString(argon2auth.Salt)
 
-	_, err := db.Pool.Exec(context.Background(), "insert into users (email, username, argon2_memory, argon2_time, argon2_threads, hashed_password, salt) values ($1, $2, $3, $4, $5, $6, $7)",
+	_, err := db.conn().Exec(ctx, "insert into users (email, username, argon2_memory, argon2_time, argon2_threads, hashed_password, salt) values ($1, $2, $3, $4, $5, $6, $7)",
 		email,
 		username,
 		mem,
@@ -175,9 +440,9 @@ func (db *DB) CreateUser(email string, username string, argon2auth *auth.Argon2A
 	return nil
 }
 
-func (db *DB) GetUserHashedPassword(username string) ([]byte, error) {
+func (db *DB) GetUserHashedPassword(ctx context.Context, username string) ([]byte, error) {
 	var hashed_password string
-	err := db.Pool.QueryRow(context.Background(), "select hashed_password from users where username=$1", username).Scan(&hashed_password)
+	err := db.conn().QueryRow(ctx, "select hashed_password from users where username=$1", username).Scan(&hashed_password)
 	if err != nil {
 		return nil, err
 	}
@@ -190,13 +455,13 @@ func (db *DB) GetUserHashedPassword(username string) ([]byte, error) {
 	return decoded_password, nil
 }
 
-func (db *DB) GetUserArgon2Auth(username string) (*auth.Argon2Auth, error) {
+func (db *DB) GetUserArgon2Auth(ctx context.Context, username string) (*auth.Argon2Auth, error) {
 	var mem int
 	var time int
 	var threads int
 	var salt string
 
-	err := db.Pool.QueryRow(context.Background(), "select argon2_memory, argon2_time, argon2_threads, salt from users where username=$1", username).Scan(&mem, &time, &threads, &salt)
+	err := db.conn().QueryRow(ctx, "select argon2_memory, argon2_time, argon2_threads, salt from users where username=$1", username).Scan(&mem, &time, &threads, &salt)
 	if err != nil {
 		return nil, err
 	}
@@ -214,4 +479,37 @@ func (db *DB) GetUserArgon2Auth(username string) (*auth.Argon2Auth, error) {
 	}
 
 	return argon2auth, nil
+}
+
+// Counts applications across all users, for metrics.
+func (db *DB) CountApplicationsByStatus(ctx context.Context) (map[ApplicationStatus]int, error) {
+	rows, err := db.conn().Query(ctx, "select status, count(*) from applications where deleted_at is null group by status")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[ApplicationStatus]int{}
+
+	for rows.Next() {
+		var status ApplicationStatus
+		var count int
+
+		err = rows.Scan(&status, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Maps the zero time to SQL null.
+func nullableTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
 }
\ No newline at end of file
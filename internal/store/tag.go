@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultTagColor  string = "#808080"
+	maxTagNameLength int    = 50
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// A user's label for applications, such as "dream job". Names are unique per user, ignoring case.
+type Tag struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"` // Hex RGB, e.g. "#1e90ff".
+}
+
+// Checks the name and colour, defaulting an empty colour to DefaultTagColor.
+func (tag *Tag) Validate() error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return errors.New("`name` is required")
+	}
+	if utf8.RuneCountInString(tag.Name) > maxTagNameLength {
+		return errors.New("`name` must be at most 50 characters")
+	}
+
+	tag.Color = strings.ToLower(strings.TrimSpace(tag.Color))
+	if tag.Color == "" {
+		tag.Color = DefaultTagColor
+	}
+	if !tagColorPattern.MatchString(tag.Color) {
+		return errors.New("`color` must be a hex colour like #1e90ff")
+	}
+
+	return nil
+}
+
+const tagColumns string = "id, name, color"
+
+func scanTag(row pgx.Row) (*Tag, error) {
+	tag := &Tag{}
+
+	err := row.Scan(&tag.ID, &tag.Name, &tag.Color)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (db *DB) queryTags(ctx context.Context, sql string, args ...any) ([]*Tag, error) {
+	rows, err := db.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*Tag{}
+
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (db *DB) ListTags(ctx context.Context, username string) ([]*Tag, error) {
+	return db.queryTags(ctx, "select "+tagColumns+" from tags where username=$1 order by lower(name), id", username)
+}
+
+func (db *DB) GetTag(ctx context.Context, username string, tagID int64) (*Tag, error) {
+	return scanTag(db.conn().QueryRow(ctx, "select "+tagColumns+" from tags where id=$1 and username=$2", tagID, username))
+}
+
+// Saves a new tag, setting its ID. Returns ErrConflict if the user already has a tag with that name.
+func (db *DB) CreateTag(ctx context.Context, username string, tag *Tag) error {
+	err := db.conn().QueryRow(ctx, "insert into tags (username, name, color) values ($1, $2, $3) returning id",
+		username,
+		tag.Name,
+		tag.Color,
+	).Scan(&tag.ID)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+
+	return err
+}
+
+// Renames or recolours the tag with ID tag.ID.
+func (db *DB) UpdateTag(ctx context.Context, username string, tag *Tag) error {
+	command_tag, err := db.conn().Exec(ctx, "update tags set name=$1, color=$2 where id=$3 and username=$4",
+		tag.Name,
+		tag.Color,
+		tag.ID,
+		username,
+	)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Deletes the tag and removes it from every application.
+func (db *DB) DeleteTag(ctx context.Context, username string, tagID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from tags where id=$1 and username=$2", tagID, username)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) ListApplicationTags(ctx context.Context, username string, companyID string) ([]*Tag, error) {
+	return db.queryTags(ctx, "select "+prefixColumns("tags", tagColumns)+` from tags
+		join application_tags on application_tags.tag_id = tags.id
+		where application_tags.username=$1 and application_tags.company=$2
+		order by lower(tags.name), tags.id`,
+		username,
+		companyID,
+	)
+}
+
+// Tags the application. Tagging twice is a no-op.
+func (db *DB) TagApplication(ctx context.Context, username string, companyID string, tagID int64) error {
+	// Both must belong to the user, so select through them rather than inserting the IDs as given.
+	_, err := db.conn().Exec(ctx, `insert into application_tags (username, company, tag_id)
+		select applications.username, applications.company, tags.id
+		from applications join tags on tags.username = applications.username
+		where applications.username=$1 and applications.company=$2 and applications.deleted_at is null and tags.id=$3
+		on conflict do nothing`,
+		username,
+		companyID,
+		tagID,
+	)
+	if err != nil {
+		return err
+	}
+
+	var tagged bool
+	err = db.conn().QueryRow(ctx, "select exists (select 1 from application_tags where username=$1 and company=$2 and tag_id=$3)", username, companyID, tagID).Scan(&tagged)
+	if err != nil {
+		return err
+	}
+	if !tagged {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) UntagApplication(ctx context.Context, username string, companyID string, tagID int64) error {
+	command_tag, err := db.conn().Exec(ctx, "delete from application_tags where username=$1 and company=$2 and tag_id=$3", username, companyID, tagID)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
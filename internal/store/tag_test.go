@@ -0,0 +1,74 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagValidate(t *testing.T) {
+	tag := &Tag{Name: " Dream job ", Color: "#1E90FF"}
+
+	err := tag.Validate()
+	if err != nil {
+		t.Fatalf("Expected a valid tag, got %v", err)
+	}
+	if tag.Name != "Dream job" || tag.Color != "#1e90ff" {
+		t.Errorf("Expected the tag to be normalised, got %+v", tag)
+	}
+
+	tag = &Tag{Name: "Referral"}
+	_ = tag.Validate()
+	if tag.Color != DefaultTagColor {
+		t.Errorf("Expected the colour to default to %q, got %q", DefaultTagColor, tag.Color)
+	}
+}
+
+func TestTagValidateInvalid(t *testing.T) {
+	cases := map[string]Tag{
+		"no name":      {Color: "#ffffff"},
+		"long name":    {Name: strings.Repeat("a", 51)},
+		"named colour": {Name: "Visa sponsor", Color: "red"},
+		"short colour": {Name: "Visa sponsor", Color: "#fff"},
+	}
+
+	for name, tag := range cases {
+		if tag.Validate() == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestApplicationFilterMatchesTags(t *testing.T) {
+	tag_names := []string{"Dream job", "Referral"}
+
+	cases := []struct {
+		name    string
+		filter  ApplicationFilter
+		matches bool
+	}{
+		{"no tags", ApplicationFilter{}, true},
+		{"any of", ApplicationFilter{Tags: []string{"visa sponsor", "dream JOB"}}, true},
+		{"any of, none held", ApplicationFilter{Tags: []string{"visa sponsor"}}, false},
+		{"all of", ApplicationFilter{Tags: []string{"referral", "dream job"}, MatchAllTags: true}, true},
+		{"all of, one missing", ApplicationFilter{Tags: []string{"referral", "visa sponsor"}, MatchAllTags: true}, false},
+	}
+
+	for _, test_case := range cases {
+		if test_case.filter.MatchesTags(tag_names) != test_case.matches {
+			t.Errorf("%s: expected MatchesTags to be %v", test_case.name, test_case.matches)
+		}
+	}
+}
+
+func TestApplicationFilterSQLConditionsTags(t *testing.T) {
+	filter := ApplicationFilter{Tags: []string{"Referral", "Dream job", "referral"}, MatchAllTags: true}
+
+	conditions, args := filter.sqlConditions([]any{"testuser"})
+
+	if len(conditions) != 1 || !strings.HasSuffix(conditions[0], "any($2)) = 2") {
+		t.Errorf("Unexpected conditions %q", conditions)
+	}
+	if tag_names, ok := args[1].([]string); !ok || strings.Join(tag_names, ",") != "dream job,referral" {
+		t.Errorf("Expected deduplicated lower case tag names, got %v", args[1])
+	}
+}
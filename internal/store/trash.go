@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// SQL condition that hides rows of `table`, such as offers, whose application is in the trash.
+func notTrashed(table string) string {
+	return `not exists (select 1 from applications where applications.username = ` + table + `.username
+		and applications.company = ` + table + `.company and applications.deleted_at is not null)`
+}
+
+// Lists the user's trashed applications, most recently deleted first.
+func (db *DB) ListTrashedApplications(ctx context.Context, username string) ([]*JobApplication, error) {
+	rows, err := db.conn().Query(ctx, "select "+applicationColumns+" from applications where username=$1 and deleted_at is not null order by deleted_at desc, company", username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applications := []*JobApplication{}
+
+	for rows.Next() {
+		job_application, err := scanApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, job_application)
+	}
+
+	return applications, rows.Err()
+}
+
+// Moves the application out of the trash, with everything attached to it.
+func (db *DB) RestoreApplication(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	command_tag, err := db.conn().Exec(ctx, `update applications set deleted_at=null, version=version+1
+		where company=$1 and username=$2 and deleted_at is not null and ($3::bigint = 0 or version=$3)`, companyID, username, expectedVersion)
+	if err != nil {
+		return err
+	}
+	if command_tag.RowsAffected() == 0 {
+		return db.explainMissedRestore(ctx, username, companyID, expectedVersion)
+	}
+
+	return nil
+}
+
+// Like explainMissedUpdate, for an application expected to be in the trash.
+func (db *DB) explainMissedRestore(ctx context.Context, username string, companyID string, expectedVersion int64) error {
+	if expectedVersion == 0 {
+		return ErrNotFound
+	}
+
+	var trashed bool
+	err := db.conn().QueryRow(ctx, "select exists (select 1 from applications where company=$1 and username=$2 and deleted_at is not null)", companyID, username).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return ErrVersionMismatch
+	}
+
+	return ErrNotFound
+}
+
+// Permanently deletes every user's applications trashed before `deletedBefore`, along with their notes,
+// interviews, offers and attachment records. Returns how many were purged and the storage keys of their
+// attachments, whose contents the caller must remove from blob storage.
+func (db *DB) PurgeDeletedApplications(ctx context.Context, deletedBefore time.Time) (int64, []string, error) {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	rows, err := tx.Query(ctx, `select attachments.storage_key
+		from attachments join applications on applications.username = attachments.username and applications.company = attachments.company
+		where applications.deleted_at < $1`, deletedBefore)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	storage_keys := []string{}
+	for rows.Next() {
+		var storage_key string
+		err = rows.Scan(&storage_key)
+		if err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		storage_keys = append(storage_keys, storage_key)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, nil, rows.Err()
+	}
+
+	command_tag, err := tx.Exec(ctx, "delete from applications where deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return command_tag.RowsAffected(), storage_keys, nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The queries DB runs, shared by pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Returns the open transaction inside WithTx, otherwise the pool.
+func (db *DB) conn() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+
+	return db.Pool
+}
+
+// Runs `fn` in a transaction, committing if it returns nil and rolling back otherwise.
+// Every method called on `tx` is part of the transaction. Calling WithTx on `tx` opens a savepoint,
+// so one step can fail and be rolled back without losing the rest.
+func (db *DB) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	tx, err := db.conn().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed.
+
+	err = fn(&DB{Pool: db.Pool, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Wraps an scs.Store so session loads and saves show up as spans within the request.
+// scs passes the request context to stores implementing scs.CtxStore.
+type SessionStore struct {
+	store  scs.Store
+	tracer trace.Tracer
+}
+
+func NewSessionStore(store scs.Store, provider trace.TracerProvider) *SessionStore {
+	return &SessionStore{
+		store:  store,
+		tracer: provider.Tracer(TracerName),
+	}
+}
+
+func (session_store *SessionStore) Find(token string) ([]byte, bool, error) {
+	return session_store.FindCtx(context.Background(), token)
+}
+
+func (session_store *SessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	return session_store.CommitCtx(context.Background(), token, b, expiry)
+}
+
+func (session_store *SessionStore) Delete(token string) error {
+	return session_store.DeleteCtx(context.Background(), token)
+}
+
+func (session_store *SessionStore) FindCtx(ctx context.Context, token string) ([]byte, bool, error) {
+	ctx, span := session_store.tracer.Start(ctx, "session find")
+	defer span.End()
+
+	if ctx_store, ok := session_store.store.(scs.CtxStore); ok {
+		return ctx_store.FindCtx(ctx, token)
+	}
+	return session_store.store.Find(token)
+}
+
+func (session_store *SessionStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	ctx, span := session_store.tracer.Start(ctx, "session commit")
+	defer span.End()
+
+	if ctx_store, ok := session_store.store.(scs.CtxStore); ok {
+		return ctx_store.CommitCtx(ctx, token, b, expiry)
+	}
+	return session_store.store.Commit(token, b, expiry)
+}
+
+func (session_store *SessionStore) DeleteCtx(ctx context.Context, token string) error {
+	ctx, span := session_store.tracer.Start(ctx, "session delete")
+	defer span.End()
+
+	if ctx_store, ok := session_store.store.(scs.CtxStore); ok {
+		return ctx_store.DeleteCtx(ctx, token)
+	}
+	return session_store.store.Delete(token)
+}
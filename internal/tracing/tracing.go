@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+// Instrumentation scope name for every tracer in the app.
+const TracerName string = "github.com/medidew/ApplicationTracker"
+
+// Builds a tracer provider exporting as configured. The returned shutdown func flushes pending spans
+// and must be called on exit. With the "none" exporter, spans are dropped at no cost.
+func NewProvider(ctx context.Context, cfg config.TracingConfig) (trace.TracerProvider, func(context.Context) error, error) {
+	no_shutdown := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+
+	switch cfg.Exporter {
+	case "none":
+		return noop.NewTracerProvider(), no_shutdown, nil
+
+	case "stdout":
+		stdout_exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter = stdout_exporter
+
+	case "file":
+		trace_file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		file_exporter, err := stdouttrace.New(stdouttrace.WithWriter(trace_file))
+		if err != nil {
+			trace_file.Close()
+			return nil, nil, err
+		}
+		exporter = file_exporter
+		closer = trace_file
+
+	case "otlp":
+		options := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		otlp_exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter = otlp_exporter
+
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	service_resource, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(service_resource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	shutdown := func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}
+
+	return provider, shutdown, nil
+}
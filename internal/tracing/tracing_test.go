@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/medidew/ApplicationTracker/internal/config"
+)
+
+func TestQueryTracerRecordsSpans(t *testing.T) {
+	span_recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(span_recorder))
+	query_tracer := NewQueryTracer(provider)
+
+	ctx := query_tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select company from applications"})
+	query_tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 2")})
+
+	ctx = query_tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "  delete from applications"})
+	query_tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+
+	spans := span_recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "db select" || spans[1].Name() != "db delete" {
+		t.Errorf("Unexpected span names %q and %q", spans[0].Name(), spans[1].Name())
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected failed query span to have error status")
+	}
+}
+
+func TestFileExporterWorksOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+
+	provider, shutdown, err := NewProvider(context.Background(), config.TracingConfig{
+		Exporter:    "file",
+		File:        path,
+		ServiceName: "test",
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error: %v", err)
+	}
+
+	_, span := provider.Tracer(TracerName).Start(context.Background(), "offline span")
+	span.End()
+
+	err = shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("shutdown() error: %v", err)
+	}
+
+	traces, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	} else if !strings.Contains(string(traces), "offline span") {
+		t.Fatalf("Expected span in trace file, got: %s", traces)
+	}
+}
@@ -2,132 +2,263 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Interview time zones must resolve even on hosts without a zoneinfo database.
 
 	"golang.org/x/term"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/alexedwards/scs/v2/memstore"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
-	"gopkg.in/yaml.v3"
 
+	"github.com/medidew/ApplicationTracker/internal/blob"
+	"github.com/medidew/ApplicationTracker/internal/config"
 	"github.com/medidew/ApplicationTracker/internal/http/handlers"
+	"github.com/medidew/ApplicationTracker/internal/logging"
+	"github.com/medidew/ApplicationTracker/internal/metrics"
 	"github.com/medidew/ApplicationTracker/internal/store"
+	"github.com/medidew/ApplicationTracker/internal/tracing"
 )
 
-const LOG_TO_CLI bool = true
+func main() {
 
-// Loads the .yaml config file from `path`.
-func loadConfig(path string) (store.DBConfig, error) {
-	cfg := store.DBConfig{}
+	/*
+		Load config
+	*/
 
-	config_file, err := os.ReadFile(path)
-	if err != nil {
-		return cfg, err
+	default_config_path := config.DefaultPath
+	if env_config_path, ok := os.LookupEnv("APP_CONFIG"); ok {
+		default_config_path = env_config_path
 	}
 
-	err = yaml.Unmarshal(config_file, &cfg)
+	config_path := flag.String("config", default_config_path, "path to the .yaml config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*config_path)
 	if err != nil {
-		return cfg, err
+		fmt.Fprintln(os.Stderr, "failed to load config: "+err.Error())
+		os.Exit(1)
 	}
 
-	return cfg, nil
-}
-
-func main() {
+	err = cfg.Validate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid config:\n"+err.Error())
+		os.Exit(1)
+	}
 
 	/*
 		Setup log
 	*/
 
-	log_file_write_syncer := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   "./logs/app.log",
-		MaxSize:    10,
-		MaxBackups: 2,
-		MaxAge:     14,
-		Compress:   false,
-	})
-
-	console_write_syncer := zapcore.Lock(os.Stdout)
-
-	encoder_cfg := zap.NewDevelopmentEncoderConfig()
-
-	core := zapcore.NewTee(
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(encoder_cfg),
-			log_file_write_syncer,
-			zap.InfoLevel,
-		),
-
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(encoder_cfg),
-			console_write_syncer,
-			zap.WarnLevel,
-		),
-	)
-
-	logger := zap.New(core)
-	defer logger.Sync()
+	logger, err := logging.New(cfg.Logging)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to construct logger: "+err.Error())
+		os.Exit(1)
+	}
 
 	logger.Info("Logger constructed")
+	logger.Info("Config loaded", zap.String("path", *config_path))
 	logger.Info("App starting")
 
+	/*
+		Setup tracing
+	*/
+
+	tracer_provider, shutdown_tracing, err := tracing.NewProvider(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Fatal("Failed to setup tracing", zap.Error(err))
+	}
+	logger.Info("Tracing configured", zap.String("exporter", cfg.Tracing.Exporter))
+
 	/*
 		DB Connect
 	*/
 
-	database_url := os.Getenv("DATABASE_URL")
+	password := ""
 
-	if database_url != "" {
-		logger.Info("DATABASE_URL env var found, skipping dbconfig...")
+	if cfg.Database.URL != "" {
+		logger.Info("Database URL set, skipping database credentials...")
 	} else {
-		cfg, err := loadConfig("configs/dbconfig.yaml") // TODO: Fix for deploy
-		if err != nil {
-			logger.Panic(err.Error())
+		password, err = cfg.Database.ResolvePassword()
+		if errors.Is(err, store.ErrNoPassword) && term.IsTerminal(int(syscall.Stdin)) {
+			fmt.Println("Enter the database password:")
+			password_bytes, err := term.ReadPassword(int(syscall.Stdin))
+			if err != nil {
+				logger.Fatal("Failed to read database password", zap.Error(err))
+			}
+			password = string(password_bytes)
+			logger.Info("Password accepted")
+		} else if err != nil {
+			logger.Fatal("Failed to resolve database password", zap.Error(err))
 		}
-		logger.Info("Config file loaded")
-
-		fmt.Println("Enter the database password:")
-		password, err := term.ReadPassword(int(syscall.Stdin))
-		if err != nil {
-			logger.Panic(err.Error())
-		}
-		logger.Info("Password accepted")
+	}
 
-		database_url = "postgresql://" + cfg.Database.User + ":" + string(password) + "@" + cfg.Database.Host + ":" + cfg.Database.Port + "/" + cfg.Database.Name
+	pool_config, err := cfg.Database.PoolConfig(password)
+	if err != nil {
+		logger.Fatal("Invalid database settings", zap.Error(err))
 	}
+	pool_config.ConnConfig.Tracer = tracing.NewQueryTracer(tracer_provider)
 
-	pool, err := pgxpool.New(context.Background(), database_url)
+	pool, err := pgxpool.NewWithConfig(context.Background(), pool_config)
 	if err != nil {
-		logger.Panic(err.Error())
+		logger.Fatal("Failed to create database pool", zap.Error(err))
+	}
+
+	db := &store.DB{Pool: pool}
+
+	if cfg.Database.MigrateOnStart {
+		applied, err := db.Migrate(context.Background())
+		if err != nil {
+			pool.Close()
+			logger.Fatal("Failed to apply migrations", zap.Error(err))
+		}
+		logger.Info("Migrations applied", zap.Strings("versions", applied))
 	}
-	defer pool.Close()
 
 	/*
 		App
 	*/
+	same_site, _ := cfg.Sessions.SameSiteMode() // Already checked by cfg.Validate().
+
+	session_store := memstore.New()
+
 	session_manager := scs.New()
-	session_manager.Lifetime = 12 * time.Hour
-	session_manager.IdleTimeout = 1 * time.Hour
-	session_manager.Cookie.Secure = false // TODO: change if ever out of dev
+	session_manager.Store = tracing.NewSessionStore(session_store, tracer_provider)
+	session_manager.Lifetime = cfg.Sessions.Lifetime
+	session_manager.IdleTimeout = cfg.Sessions.IdleTimeout
+	session_manager.Cookie.Secure = cfg.Sessions.CookieSecure
 	session_manager.Cookie.HttpOnly = true
-	session_manager.Cookie.SameSite = http.SameSiteLaxMode
+	session_manager.Cookie.SameSite = same_site
+
+	app_metrics := metrics.New()
+
+	err = errors.Join(
+		app_metrics.RegisterPool(pool),
+		app_metrics.RegisterSessions(session_store),
+		app_metrics.RegisterStore(db),
+	)
+	if err != nil {
+		logger.Fatal("Failed to register metrics", zap.Error(err))
+	}
+
+	blobs, err := blob.New(cfg.Attachments)
+	if err != nil {
+		logger.Fatal("Failed to set up attachment storage", zap.Error(err))
+	}
 
 	app := &handlers.App{
-		DB:     &store.DB{Pool: pool},
+		DB:     db,
+		Blobs:  blobs,
 		Logger: logger,
 		SessionManager: session_manager,
+		Config: cfg,
+		Metrics: app_metrics,
+		TracerProvider: tracer_provider,
 	}
 
 	router := handlers.SetupRouter(app)
 
-	logger.Info("Starting HTTP server on :4000")
+	/*
+		Background workers
+	*/
+
+	workers := newWorkerGroup(logger)
+	workers.Start("trash purge", purgeTrash(db, blobs, cfg.Trash, logger))
+
+	archive_rules, _ := cfg.Archive.StoreRules() // Already checked by cfg.Validate().
+	if len(archive_rules) > 0 {
+		workers.Start("auto-archive", autoArchive(db, archive_rules, cfg.Archive.Interval, logger))
+	}
+
+	workers.Start("idempotency key purge", purgeIdempotencyKeys(db, cfg.Idempotency.PurgeInterval, logger))
+
+	/*
+		Serve
+	*/
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          zap.NewStdLog(logger),
+	}
+
+	exit_code := 0
+
+	err = serve(server, cfg.Server.ShutdownTimeout, logger)
+	if err != nil {
+		logger.Error("HTTP server failed", zap.Error(err))
+		exit_code = 1
+	}
+
+	/*
+		Teardown, in reverse order of startup
+	*/
 
-	http.ListenAndServe(":4000", router)
-}
\ No newline at end of file
+	workers.Stop()
+	logger.Info("Background workers stopped")
+
+	session_store.StopCleanup()
+	logger.Info("Session cleanup stopped")
+
+	pool.Close()
+	logger.Info("Database pool closed")
+
+	// Last, so spans from the drain and teardown are flushed.
+	tracing_ctx, cancel_tracing := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	err = shutdown_tracing(tracing_ctx)
+	cancel_tracing()
+	if err != nil {
+		logger.Error("Failed to flush traces", zap.Error(err))
+	}
+
+	logger.Info("Shutdown complete")
+	logger.Sync()
+
+	os.Exit(exit_code)
+}
+
+// Runs `server` until it fails or SIGINT/SIGTERM is received, then drains connections for up to `drain`.
+func serve(server *http.Server, drain time.Duration, logger *zap.Logger) error {
+	signal_ctx, stop_signals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop_signals()
+
+	server_errors := make(chan error, 1)
+	go func() {
+		logger.Info("Starting HTTP server on " + server.Addr)
+		server_errors <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-server_errors:
+		// ListenAndServe only returns ErrServerClosed after Shutdown, so anything here is a failure.
+		return err
+	case <-signal_ctx.Done():
+	}
+
+	logger.Info("Shutdown signal received, draining connections", zap.Duration("drain", drain))
+	stop_signals() // A second signal kills the process immediately.
+
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+
+	err := server.Shutdown(shutdown_ctx)
+	if err != nil {
+		server.Close()
+		return errors.Join(errors.New("failed to drain connections"), err)
+	}
+
+	logger.Info("HTTP server stopped")
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/medidew/ApplicationTracker/internal/blob"
+	"github.com/medidew/ApplicationTracker/internal/config"
+	"github.com/medidew/ApplicationTracker/internal/store"
+)
+
+// Tracks background goroutines so teardown can stop them before the resources they use are closed.
+type workerGroup struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	logger  *zap.Logger
+}
+
+func newWorkerGroup(logger *zap.Logger) *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &workerGroup{
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger,
+	}
+}
+
+// Runs `work` in the background. `work` must return once its context is cancelled.
+func (group *workerGroup) Start(name string, work func(ctx context.Context)) {
+	group.running.Add(1)
+
+	go func() {
+		defer group.running.Done()
+
+		group.logger.Info("Background worker started", zap.String("worker", name))
+		work(group.ctx)
+		group.logger.Info("Background worker stopped", zap.String("worker", name))
+	}()
+}
+
+// Cancels every worker and waits for them all to return.
+func (group *workerGroup) Stop() {
+	group.cancel()
+	group.running.Wait()
+}
+
+// Calls `work` straight away and then every `interval` until `ctx` is cancelled.
+func runEvery(ctx context.Context, interval time.Duration, work func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		work()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Permanently deletes applications that have been in the trash for longer than the retention period,
+// once at startup and then every purge interval.
+func purgeTrash(db store.Store, blobs blob.Storage, cfg config.TrashConfig, logger *zap.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		runEvery(ctx, cfg.PurgeInterval, func() {
+			purged, storage_keys, err := db.PurgeDeletedApplications(ctx, time.Now().Add(-cfg.Retention))
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Failed to purge trashed applications", zap.Error(err))
+			}
+			if purged > 0 {
+				logger.Info("Purged trashed applications", zap.Int64("applications", purged), zap.Int("attachments", len(storage_keys)))
+			}
+
+			// The records are already gone, so a file that fails to delete is only logged.
+			for _, storage_key := range storage_keys {
+				err = blobs.Delete(ctx, storage_key)
+				if err != nil && !errors.Is(err, blob.ErrNotFound) {
+					logger.Warn("Failed to remove purged attachment", zap.String("key", storage_key), zap.Error(err))
+				}
+			}
+		})
+	}
+}
+
+// Applies the auto-archive rules, once at startup and then every interval.
+func autoArchive(db store.Store, rules []store.ArchiveRule, interval time.Duration, logger *zap.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		runEvery(ctx, interval, func() {
+			archived, err := db.ArchiveStaleApplications(ctx, rules, time.Now())
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Failed to auto-archive applications", zap.Error(err))
+			}
+			if archived > 0 {
+				logger.Info("Auto-archived applications", zap.Int64("applications", archived))
+			}
+		})
+	}
+}
+
+// Deletes idempotency keys whose responses are no longer replayed, once at startup and then every purge interval.
+func purgeIdempotencyKeys(db store.Store, interval time.Duration, logger *zap.Logger) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		runEvery(ctx, interval, func() {
+			purged, err := db.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Failed to purge idempotency keys", zap.Error(err))
+			}
+			if purged > 0 {
+				logger.Info("Purged expired idempotency keys", zap.Int64("keys", purged))
+			}
+		})
+	}
+}